@@ -16,6 +16,7 @@ type CellLocation struct {
 	DataType DataType
 	Name     string // Alias that will be used if not blank
 	NameCell Cell   // Location for the name cell. Will be ignored if Name is not blank
+	Options  *DataTypeOptions
 }
 
 func NewCellLocation(location Cell, dataType DataType, name string, nameCell Cell) (*CellLocation, error) {
@@ -32,21 +33,34 @@ func NewCellLocation(location Cell, dataType DataType, name string, nameCell Cel
 
 // parses a cell's information from records of a csv file
 func (c *CellLocation) Parse(records [][]string) (name string, data any, err error) {
+	name, data, _, err = c.parse(records, nil, nil)
+	return name, data, err
+}
+
+// parses the cell with the options of the cell merged over the parent options.
+// The source text of the value is returned as raw and conversion errors recovered by
+// the OnError policy are added to the report
+func (c *CellLocation) parse(records [][]string, options *DataTypeOptions, report *Report) (name string, data any, raw string, err error) {
 	cellName := c.Name
 	if cellName == "" {
 		cellName, err = findValue(c.NameCell, records)
 		if err != nil {
-			return "", nil, fmt.Errorf("error finding name for cell: %w", err)
+			return "", nil, "", fmt.Errorf("error finding name for cell: %w", err)
 		}
 	}
 
 	value, err := findValue(c.Location, records)
 	if err != nil {
-		return "", nil, fmt.Errorf("error finding value for cell: %w", err)
+		return "", nil, "", fmt.Errorf("error finding value for cell: %w", err)
 	}
-	cellData, err := c.DataType.Read(value)
+	options = options.merge(c.Options)
+	cellData, err := c.DataType.ReadWithOptions(value, options)
 	if err != nil {
-		return "", nil, fmt.Errorf("error converting value to data type: %w", err)
+		warning := Warning{Row: c.Location.Row, Column: c.Location.Column, Header: cellName}
+		cellData, err = options.recoverError(err, value, warning, report)
+		if err != nil {
+			return "", nil, "", fmt.Errorf("error converting value to data type: %w", err)
+		}
 	}
-	return cellName, cellData, nil
+	return cellName, cellData, value, nil
 }
@@ -12,6 +12,7 @@ type ConcatCellLocation struct {
 	Name      string // Alias that will be used if not blank
 	NameCell  Cell   // Location for the name cell. Will be ignored if Name is not blank
 	DataType  DataType
+	Options   *DataTypeOptions
 }
 
 func NewConcatCellLocation(cells []Cell, delimiter string, name string, nameCell Cell) (*ConcatCellLocation, error) {
@@ -28,12 +29,20 @@ func NewConcatCellLocation(cells []Cell, delimiter string, name string, nameCell
 
 // parses and concatenates multiple cells information from records of a csv file
 func (c *ConcatCellLocation) Parse(records [][]string) (string, any, error) {
+	name, data, _, err := c.parse(records, nil, nil)
+	return name, data, err
+}
+
+// parses the cells with the options of the concat cell merged over the parent options.
+// The concatenated source text is returned as raw and conversion errors recovered by
+// the OnError policy are added to the report
+func (c *ConcatCellLocation) parse(records [][]string, options *DataTypeOptions, report *Report) (string, any, string, error) {
 	var err error
 	name := c.Name
 	if name == "" {
 		name, err = findValue(c.NameCell, records)
 		if err != nil {
-			return "", nil, fmt.Errorf("error finding name for cell: %w", err)
+			return "", nil, "", fmt.Errorf("error finding name for cell: %w", err)
 		}
 	}
 
@@ -41,17 +50,25 @@ func (c *ConcatCellLocation) Parse(records [][]string) (string, any, error) {
 	for _, cell := range c.Cells {
 		value, err := findValue(cell, records)
 		if err != nil {
-			return "", nil, fmt.Errorf("error finding value for cell (%d, %d): %w", cell.Row, cell.Column, err)
+			return "", nil, "", fmt.Errorf("error finding value for cell (%d, %d): %w", cell.Row, cell.Column, err)
 		}
 		values = append(values, value)
 	}
 
 	value := strings.Join(values, c.Delimiter)
 
-	data, err := c.DataType.Read(value)
+	options = options.merge(c.Options)
+	data, err := c.DataType.ReadWithOptions(value, options)
 	if err != nil {
-		return "", nil, fmt.Errorf("error converting value to data type; %w", err)
+		warning := Warning{Row: -1, Column: -1, Header: name}
+		if len(c.Cells) > 0 {
+			warning.Row, warning.Column = c.Cells[0].Row, c.Cells[0].Column
+		}
+		data, err = options.recoverError(err, value, warning, report)
+		if err != nil {
+			return "", nil, "", fmt.Errorf("error converting value to data type; %w", err)
+		}
 	}
 
-	return name, data, nil
+	return name, data, value, nil
 }
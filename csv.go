@@ -21,6 +21,12 @@ type Csv struct {
 	KeepSpaces          bool
 	StoreFileTime       bool
 	FileTimeName        string
+	Options             *DataTypeOptions // Default options for all cells, concat cells, tables and time fields
+
+	// Key of the FilePathData which selects the location of the file. The value
+	// is looked up in FilePathLocations or used as the IANA name if it is empty
+	LocationFromFilePath string
+	FilePathLocations    map[string]string
 }
 
 func NewCsvFile(cellLocations []CellLocation, concatCellLocations []ConcatCellLocation, tableLocations []TableLocation) *Csv {
@@ -33,8 +39,11 @@ func NewCsvFile(cellLocations []CellLocation, concatCellLocations []ConcatCellLo
 }
 
 func (c Csv) MarshalJSON() ([]byte, error) {
-	for _, processor := range c.PreProcessor {
+	for n, processor := range c.PreProcessor {
 		processor.SetType()
+		if err := checkProcessorType(processor); err != nil {
+			return nil, fmt.Errorf("error marshaling processor %d: %w", n, err)
+		}
 	}
 	type Alias Csv
 	return json.Marshal(Alias(c))
@@ -99,66 +108,98 @@ func (c *Csv) Process(filepath string) (result [][]byte, id []string, err error)
 //
 // Will output either a map[string]any or []map[string]any
 func (c *Csv) ParseFile(filePath string) ([]map[string]any, []string, error) {
+	output, ids, _, err := c.ParseFileWithReport(filePath)
+	return output, ids, err
+}
+
+// Parse a file and return all the results grouped along with a report of
+// the data that was adjusted while parsing
+func (c *Csv) ParseFileWithReport(filePath string) ([]map[string]any, []string, *Report, error) {
 	filePathData, err := c.ParseFileNames(filePath)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error parsing filePath: %w", err)
+		return nil, nil, nil, fmt.Errorf("error parsing filePath: %w", err)
+	}
+
+	options, err := c.fileOptions(filePathData)
+	if err != nil {
+		return nil, nil, nil, fmt.Errorf("error finding options for file: %w", err)
+	}
+
+	fileData := make(map[string]any, len(filePathData)+1)
+	for key, value := range filePathData {
+		fileData[key] = value
 	}
 
 	if c.StoreFileTime {
 		if c.FileTimeName == "" {
-			return nil, nil, fmt.Errorf("storeFileTime is true but not FileTimeName provided")
+			return nil, nil, nil, fmt.Errorf("storeFileTime is true but not FileTimeName provided")
 		}
 
 		timeVal, err := getCreationTime(filePath)
 		if err != nil {
-			return nil, nil, fmt.Errorf("cannot get fileTime: %w", err)
+			return nil, nil, nil, fmt.Errorf("cannot get fileTime: %w", err)
 		}
 
 		if c.FaultOnDuplicate {
 			if value, exists := filePathData[c.FileTimeName]; exists {
-				return nil, nil, fmt.Errorf("fileTimeName, %s, already exists in filePathData with value %v", c.FileTimeName, value)
+				return nil, nil, nil, fmt.Errorf("fileTimeName, %s, already exists in filePathData with value %v", c.FileTimeName, value)
 			}
 		}
-		filePathData[c.FileTimeName] = timeVal.Format(time.RFC3339)
+		location, err := loadLocation(options.Location)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("cannot get location for fileTime: %w", err)
+		}
+		fileData[c.FileTimeName], err = formatTime(timeVal.In(location), options)
+		if err != nil {
+			return nil, nil, nil, fmt.Errorf("cannot format fileTime: %w", err)
+		}
 	}
 
 	records, err := getRecords(filePath)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error getting records: %w", err)
+		return nil, nil, nil, fmt.Errorf("error getting records: %w", err)
 	}
 
+	var processorReports []ProcessorReport
 	for n, processor := range c.PreProcessor {
-		records, err = processor.Execute(records)
+		if counting, ok := processor.(CountingProcessor); ok {
+			var removed int
+			records, removed, err = counting.ExecuteWithCount(records)
+			processorReports = append(processorReports, ProcessorReport{Name: processor.GetName(), Type: processor.GetType(), Removed: removed})
+		} else {
+			records, err = processor.Execute(records)
+		}
 		if err != nil {
-			return nil, nil, fmt.Errorf("error preprocessing records with processor %d (%s): %w", n, processor.GetName(), err)
+			return nil, nil, nil, fmt.Errorf("error preprocessing records with processor %d (%s): %w", n, processor.GetName(), err)
 		}
 	}
 
-	output, err := c.ParseRecords(records)
+	output, report, err := c.parseRecords(records, options)
 	if err != nil {
-		return nil, nil, fmt.Errorf("error parsing records: %w", err)
+		return nil, nil, nil, fmt.Errorf("error parsing records: %w", err)
 	}
+	report.Processors = processorReports
 
 	var outputData []map[string]any
 	switch output := output.(type) {
 	case map[string]any:
-		for key, value := range filePathData {
+		for key, value := range fileData {
 			if c.FaultOnDuplicate {
 				_, exists := output[key]
 				if exists {
-					return nil, nil, fmt.Errorf("%s exists in csv data. FilePath data: %v | Output data: %v", key, value, output[key])
+					return nil, nil, nil, fmt.Errorf("%s exists in csv data. FilePath data: %v | Output data: %v", key, value, output[key])
 				}
 			}
 			output[key] = value
 		}
 		outputData = []map[string]any{output}
 	case []map[string]any:
-		for key, value := range filePathData {
+		for key, value := range fileData {
 			for _, data := range output {
 				if c.FaultOnDuplicate {
 					_, exists := data[key]
 					if exists {
-						return nil, nil, fmt.Errorf("%s exists in csv data. FilePath data: %v | Output data: %v", key, value, data[key])
+						return nil, nil, nil, fmt.Errorf("%s exists in csv data. FilePath data: %v | Output data: %v", key, value, data[key])
 					}
 				}
 				data[key] = value
@@ -166,18 +207,38 @@ func (c *Csv) ParseFile(filePath string) ([]map[string]any, []string, error) {
 		}
 		outputData = output
 	default:
-		return nil, nil, fmt.Errorf("invalid output type: %T", output)
+		return nil, nil, nil, fmt.Errorf("invalid output type: %T", output)
 	}
 
 	var ids []string
 	if len(c.IdField.Parameters) > 0 {
 		ids, err = c.IdField.Process(outputData)
 		if err != nil {
-			return nil, nil, fmt.Errorf("error processing IdField: %w", err)
+			return nil, nil, nil, fmt.Errorf("error processing IdField: %w", err)
 		}
 	}
 
-	return outputData, ids, nil
+	return outputData, ids, report, nil
+}
+
+// resolves the options of a file using the location selected by its file path
+func (c *Csv) fileOptions(filePathData map[string]string) (*DataTypeOptions, error) {
+	if c.LocationFromFilePath == "" {
+		return c.Options.merge(nil), nil
+	}
+
+	value, exists := filePathData[c.LocationFromFilePath]
+	if !exists {
+		return nil, fmt.Errorf("locationFromFilePath, %s, not found in filePathData", c.LocationFromFilePath)
+	}
+	location := value
+	if len(c.FilePathLocations) > 0 {
+		location, exists = c.FilePathLocations[value]
+		if !exists {
+			return nil, fmt.Errorf("no location provided in filePathLocations for %s", value)
+		}
+	}
+	return c.Options.merge(&DataTypeOptions{Location: location}), nil
 }
 
 func (c *Csv) ParseFileNames(filePath string) (map[string]string, error) {
@@ -198,39 +259,71 @@ func (c *Csv) ParseFileNames(filePath string) (map[string]string, error) {
 }
 
 func (c *Csv) ParseRecords(records [][]string) (any, error) {
+	output, _, err := c.ParseRecordsWithReport(records)
+	return output, err
+}
+
+// Parses the records and returns a report of the data that was adjusted while parsing
+func (c *Csv) ParseRecordsWithReport(records [][]string) (any, *Report, error) {
+	return c.parseRecords(records, c.Options)
+}
+
+func (c *Csv) parseRecords(records [][]string, options *DataTypeOptions) (any, *Report, error) {
+	report := &Report{}
 	baseData := make(map[string]any)
+
+	// Parse Timestamp first so it can be used as the base of time offsets
+	timestamps := make(map[string]time.Time, len(c.TimeFields))
+	for _, timeField := range c.TimeFields {
+		if timeField.IsBlank() {
+			continue
+		}
+
+		timestamp, err := timeField.parse(records, options)
+		if err != nil {
+			return nil, nil, err
+		}
+		timestamps[timeField.Name] = timestamp
+	}
+	options = options.merge(nil)
+	options.baseTimes = timestamps
+
 	// Parse Cells
 	for _, cellLocation := range c.CellLocations {
-		name, data, err := cellLocation.Parse(records)
+		name, data, raw, err := cellLocation.parse(records, options, report)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		if !c.KeepSpaces {
 			name = strings.ReplaceAll(name, " ", "_")
 		}
 		if c.FaultOnDuplicate {
 			if _, exists := baseData[name]; exists {
-				return nil, fmt.Errorf("duplicate data found for cell (%s)", name)
+				return nil, nil, fmt.Errorf("duplicate data found for cell (%s)", name)
 			}
 		}
-		baseData[name] = data
+		if err = options.merge(cellLocation.Options).setValue(baseData, name, data, raw); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	// Parse ConcatCells
 	for _, concatCellLocation := range c.ConcatCellLocations {
-		name, data, err := concatCellLocation.Parse(records)
+		name, data, raw, err := concatCellLocation.parse(records, options, report)
 		if err != nil {
-			return nil, err
+			return nil, nil, err
 		}
 		if !c.KeepSpaces {
 			name = strings.ReplaceAll(name, " ", "_")
 		}
 		if c.FaultOnDuplicate {
 			if _, exists := baseData[name]; exists {
-				return nil, fmt.Errorf("duplicate data found for concatCell (%s)", name)
+				return nil, nil, fmt.Errorf("duplicate data found for concatCell (%s)", name)
 			}
 		}
-		baseData[name] = data
+		if err = options.merge(concatCellLocation.Options).setValue(baseData, name, data, raw); err != nil {
+			return nil, nil, err
+		}
 	}
 
 	// Parse non-separated Tables
@@ -239,34 +332,34 @@ func (c *Csv) ParseRecords(records [][]string) (any, error) {
 			continue
 		}
 
-		tableName, tableData, err := tableLocation.Parse(records, c.KeepSpaces)
+		tableName, tableData, err := tableLocation.parse(records, c.KeepSpaces, options, report)
 		if err != nil {
-			return nil, fmt.Errorf("error parsing table (%s): %w", tableName, err)
+			return nil, nil, fmt.Errorf("error parsing table (%s): %w", tableName, err)
 		}
 		if c.FaultOnDuplicate {
 			if _, exists := baseData[tableName]; exists {
-				return nil, fmt.Errorf("duplicate data found for table (%s)", tableName)
+				return nil, nil, fmt.Errorf("duplicate data found for table (%s)", tableName)
 			}
 		}
 		baseData[tableName] = tableData
 	}
 
-	// Parse Timestamp
+	// Add Timestamp
 	for _, timeField := range c.TimeFields {
-		if !timeField.IsBlank() {
+		timestamp, exists := timestamps[timeField.Name]
+		if !exists {
 			continue
 		}
-
-		timestamp, err := timeField.Parse(records)
-		if err != nil {
-			return nil, err
-		}
 		if c.FaultOnDuplicate {
 			if value, exists := baseData[timeField.Name]; exists {
-				return nil, fmt.Errorf("duplicate key found for @timestamp with value: %v", value)
+				return nil, nil, fmt.Errorf("duplicate key found for @timestamp with value: %v", value)
 			}
 		}
-		baseData[timeField.Name] = timestamp.Format(time.RFC3339)
+		formatted, err := formatTime(timestamp, options)
+		if err != nil {
+			return nil, nil, fmt.Errorf("error formatting time field %s: %w", timeField.Name, err)
+		}
+		baseData[timeField.Name] = formatted
 	}
 
 	var csvData []map[string]any
@@ -277,16 +370,16 @@ func (c *Csv) ParseRecords(records [][]string) (any, error) {
 		}
 
 		if tableLocation.ParseAsArray || tableLocation.ParseSingleRow {
-			return nil, fmt.Errorf("invalid options selection. Cannot parse as array or single row if parsing separated for table, %s", tableLocation.Name)
+			return nil, nil, fmt.Errorf("invalid options selection. Cannot parse as array or single row if parsing separated for table, %s", tableLocation.Name)
 		}
 
-		tableName, tableData, err := tableLocation.Parse(records, c.KeepSpaces)
+		tableName, tableData, err := tableLocation.parse(records, c.KeepSpaces, options, report)
 		if err != nil {
-			return nil, fmt.Errorf("error parsing table (%s): %w", tableName, err)
+			return nil, nil, fmt.Errorf("error parsing table (%s): %w", tableName, err)
 		}
 		if c.FaultOnDuplicate {
 			if _, exists := baseData[tableName]; exists {
-				return nil, fmt.Errorf("duplicate data found for table (%s)", tableName)
+				return nil, nil, fmt.Errorf("duplicate data found for table (%s)", tableName)
 			}
 		}
 
@@ -308,23 +401,45 @@ func (c *Csv) ParseRecords(records [][]string) (any, error) {
 				csvData = append(csvData, instance)
 			}
 		default:
-			return nil, fmt.Errorf("table is of wrong type: %T", tableData)
+			return nil, nil, fmt.Errorf("table is of wrong type: %T", tableData)
 		}
 	}
 
 	if len(csvData) == 0 {
-		return baseData, nil
+		return baseData, report, nil
 	}
 
-	return csvData, nil
+	return csvData, report, nil
 }
 
 // parse a file and return all results per type of search
 func (c *Csv) ParseRecordsSegmented(records [][]string) (cells map[string]any, concatCells map[string]any, tables map[string]any, timestamps map[string]time.Time, err error) {
+	// Parse Timestamp first so it can be used as the base of time offsets
+	timeFields := make(map[string]time.Time)
+	for _, timeField := range c.TimeFields {
+		if timeField.IsBlank() {
+			continue
+		}
+
+		timestamp, err := timeField.parse(records, c.Options)
+		if err != nil {
+			return nil, nil, nil, nil, err
+		}
+
+		if c.FaultOnDuplicate {
+			if value, exists := timeFields[timeField.Name]; exists {
+				return nil, nil, nil, nil, fmt.Errorf("duplicate key found for timeField %s with value %v", timeField.Name, value)
+			}
+		}
+		timeFields[timeField.Name] = timestamp
+	}
+	options := c.Options.merge(nil)
+	options.baseTimes = timeFields
+
 	// Parse Cells
 	Cells := make(map[string]any)
 	for _, cellLocation := range c.CellLocations {
-		name, data, err := cellLocation.Parse(records)
+		name, data, raw, err := cellLocation.parse(records, options, nil)
 		if err != nil {
 			return nil, nil, nil, nil, err
 		}
@@ -336,13 +451,15 @@ func (c *Csv) ParseRecordsSegmented(records [][]string) (cells map[string]any, c
 				return nil, nil, nil, nil, fmt.Errorf("duplicate data found for cell (%s)", name)
 			}
 		}
-		Cells[name] = data
+		if err = options.merge(cellLocation.Options).setValue(Cells, name, data, raw); err != nil {
+			return nil, nil, nil, nil, err
+		}
 	}
 
 	// Parse ConcatCells
 	ConcatCells := make(map[string]any)
 	for _, concatCellLocation := range c.ConcatCellLocations {
-		name, data, err := concatCellLocation.Parse(records)
+		name, data, raw, err := concatCellLocation.parse(records, options, nil)
 		if err != nil {
 			return nil, nil, nil, nil, err
 		}
@@ -354,13 +471,15 @@ func (c *Csv) ParseRecordsSegmented(records [][]string) (cells map[string]any, c
 				return nil, nil, nil, nil, fmt.Errorf("duplicate data found for concatCell (%s)", name)
 			}
 		}
-		ConcatCells[name] = data
+		if err = options.merge(concatCellLocation.Options).setValue(ConcatCells, name, data, raw); err != nil {
+			return nil, nil, nil, nil, err
+		}
 	}
 
 	// Parse Tables
 	Tables := make(map[string]any)
 	for _, tableLocation := range c.TableLocations {
-		tableName, tableData, err := tableLocation.Parse(records, c.KeepSpaces)
+		tableName, tableData, err := tableLocation.parse(records, c.KeepSpaces, options, nil)
 		if err != nil {
 			return nil, nil, nil, nil, err
 		}
@@ -372,26 +491,6 @@ func (c *Csv) ParseRecordsSegmented(records [][]string) (cells map[string]any, c
 		Tables[tableName] = tableData
 	}
 
-	// Parse Timestamp
-	timeFields := make(map[string]time.Time)
-	for _, timeField := range c.TimeFields {
-		if !timeField.IsBlank() {
-			continue
-		}
-
-		timestamp, err := timeField.Parse(records)
-		if err != nil {
-			return nil, nil, nil, nil, err
-		}
-
-		if c.FaultOnDuplicate {
-			if value, exists := timeFields[timeField.Name]; exists {
-				return nil, nil, nil, nil, fmt.Errorf("duplicate key found for timeField %s with value %v", timeField.Name, value)
-			}
-		}
-		timeFields[timeField.Name] = timestamp
-	}
-
 	return Cells, ConcatCells, Tables, timeFields, nil
 }
 
@@ -5,6 +5,7 @@ import (
 	"os"
 	"path/filepath"
 	"reflect"
+	"strings"
 	"testing"
 	"time"
 )
@@ -208,6 +209,40 @@ func TestTableValuesAsArrayFixed(t *testing.T) {
 	}
 }
 
+func TestTimeFieldsBlank(t *testing.T) {
+	t.Parallel()
+
+	records := [][]string{
+		{"stamp", "2024-09-23 08:04:18Z"},
+	}
+	c := Csv{
+		TimeFields: []TimeField{
+			{Name: "@timestamp", Cells: []Cell{{Row: 0, Column: 1}}, Layout: "2006-01-02 15:04:05Z07:00"},
+			{Name: "noLayout", Cells: []Cell{{Row: 0, Column: 1}}},
+			{Name: "noCells", Layout: "2006-01-02 15:04:05Z07:00"},
+		},
+	}
+
+	// time fields with a layout and cells are parsed and blank time fields are left out
+	data, err := c.ParseRecords(records)
+	if err != nil {
+		t.Fatalf("failed to parse records: %v", err)
+	}
+	expected := map[string]any{"@timestamp": "2024-09-23T08:04:18Z"}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("records do not match\nexpected: %v\nreceived: %v", expected, data)
+	}
+
+	_, _, _, timestamps, err := c.ParseRecordsSegmented(records)
+	if err != nil {
+		t.Fatalf("failed to parse segmented records: %v", err)
+	}
+	expectedTimestamps := map[string]time.Time{"@timestamp": time.Date(2024, 9, 23, 8, 4, 18, 0, time.UTC)}
+	if !reflect.DeepEqual(timestamps, expectedTimestamps) {
+		t.Errorf("timestamps do not match\nexpected: %v\nreceived: %v", expectedTimestamps, timestamps)
+	}
+}
+
 func TestCsvStyle1(t *testing.T) {
 	t.Parallel()
 
@@ -546,3 +581,1069 @@ ms,m/s,MPa,mm,KPa
 		t.Errorf("fileTime %s does not match expectedFileTime %s", fileTime, expectedFileTime.Format(time.RFC3339))
 	}
 }
+
+func TestTableUnpivot(t *testing.T) {
+	t.Parallel()
+
+	records := [][]string{
+		{"Shot", "989392"},
+		{"Time", "Spd", "Pres"},
+		{"ms", "m/s", "MPa"},
+		{"0", "0.000", "0.3"},
+		{"20", "0.193", ""},
+	}
+
+	csv := Csv{
+		CellLocations: []CellLocation{
+			{NameCell: Cell{Row: 0, Column: 0}, Location: Cell{Row: 0, Column: 1}, DataType: DataTypeInt64},
+		},
+		TableLocations: []TableLocation{
+			{
+				Name:                "wave",
+				StartCell:           Cell{Row: 1, Column: 0},
+				EndCell:             Cell{Row: -1, Column: -1},
+				TableHasHeader:      true,
+				UnitRow:             true,
+				AutoColumnDataTypes: true,
+				SkipBlankData:       true,
+				ParseSeparated:      true,
+				IgnoreNesting:       true,
+				Unpivot: &Unpivot{
+					KeyColumns: []string{"Time"},
+					ValueName:  "reading",
+					Units:      map[string]string{"Pres": "kPa"},
+				},
+			},
+		},
+	}
+
+	data, err := csv.ParseRecords(records)
+	if err != nil {
+		t.Fatalf("failed to parse data: %v", err)
+	}
+
+	expected := []map[string]any{
+		{"Shot": int64(989392), "Time": Float64(0), "metric": "Spd", "reading": Float64(0), "unit": "m/s"},
+		{"Shot": int64(989392), "Time": Float64(0), "metric": "Pres", "reading": Float64(0.3), "unit": "kPa"},
+		{"Shot": int64(989392), "Time": Float64(20), "metric": "Spd", "reading": Float64(0.193), "unit": "m/s"},
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("unpivoted data does not match\nexpected: %v\nreceived: %v", expected, data)
+	}
+
+	csv.TableLocations[0].Unpivot.ValueColumns = []string{"Missing"}
+	if _, err = csv.ParseRecords(records); err == nil {
+		t.Errorf("expected error for missing value column")
+	}
+
+	csv.TableLocations[0].Unpivot.ValueColumns = nil
+	csv.TableLocations[0].AutoColumnDataTypes = false
+	csv.TableLocations[0].ColumnDataTypes = []DataType{DataTypeAuto, DataTypeSplit, DataTypeAuto}
+	if _, err = csv.ParseRecords(records); err == nil {
+		t.Errorf("expected error for unpivoting a split column")
+	}
+}
+
+func TestTableVertical(t *testing.T) {
+	t.Parallel()
+
+	records := [][]string{
+		{"Report", "Press 4"},
+		{"Shot", "1", "2", "3"},
+		{"Cycle", "55.3", "56.2"},
+		{"Result", "ok", "ng", "ok"},
+	}
+	table := TableLocation{
+		Name:            "shots",
+		StartCell:       Cell{Row: 1, Column: 0},
+		EndCell:         Cell{Row: -1, Column: -1},
+		TableHasHeader:  true,
+		ColumnDataTypes: []DataType{DataTypeInt64, DataTypeAuto, DataTypeBool},
+		SkipBlankData:   true,
+		Orientation:     TableOrientationVertical,
+		RaggedRows:      RaggedRowsPad,
+	}
+
+	_, data, err := table.Parse(records, false)
+	if err != nil {
+		t.Fatalf("failed to parse table: %v", err)
+	}
+	expected := []map[string]any{
+		{"Shot": int64(1), "Cycle": Float64(55.3), "Result": true},
+		{"Shot": int64(2), "Cycle": Float64(56.2), "Result": false},
+		{"Shot": int64(3), "Result": true},
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("table does not match\nexpected: %v\nreceived: %v", expected, data)
+	}
+
+	single := table
+	single.ParseSingleRow = true
+	_, data, err = single.Parse(records, false)
+	if err != nil {
+		t.Fatalf("failed to parse single row table: %v", err)
+	}
+	if !reflect.DeepEqual(data, expected[0]) {
+		t.Errorf("single row does not match\nexpected: %v\nreceived: %v", expected[0], data)
+	}
+
+	array := table
+	array.ParseAsArray = true
+	array.SkipBlankData = false
+	_, data, err = array.Parse(records, false)
+	if err != nil {
+		t.Fatalf("failed to parse array table: %v", err)
+	}
+	expectedArray := map[string][]any{
+		"Shot":   {int64(1), int64(2), int64(3)},
+		"Cycle":  {Float64(55.3), Float64(56.2), nil},
+		"Result": {true, false, true},
+	}
+	if !reflect.DeepEqual(data, expectedArray) {
+		t.Errorf("array does not match\nexpected: %v\nreceived: %v", expectedArray, data)
+	}
+
+	separated := table
+	separated.ParseSeparated = true
+	csv := Csv{
+		CellLocations:  []CellLocation{{NameCell: Cell{Row: 0, Column: 0}, Location: Cell{Row: 0, Column: 1}, DataType: DataTypeString}},
+		TableLocations: []TableLocation{separated},
+	}
+	output, err := csv.ParseRecords(records)
+	if err != nil {
+		t.Fatalf("failed to parse separated table: %v", err)
+	}
+	documents := output.([]map[string]any)
+	if len(documents) != 3 {
+		t.Fatalf("expected 3 documents instead of %d", len(documents))
+	} else if documents[2]["Report"] != "Press 4" || !reflect.DeepEqual(documents[2]["shots"], expected[2]) {
+		t.Errorf("separated document does not match: %v", documents[2])
+	}
+}
+
+func TestTableRaggedRows(t *testing.T) {
+	t.Parallel()
+
+	records := [][]string{
+		{"Shot", "Cycle", "Result"},
+		{"1", "55.3", "ok"},
+		{"2", "56.2"},
+		{"3"},
+	}
+	verticalRecords := [][]string{
+		{"Shot", "1", "2", "3"},
+		{"Cycle", "55.3", "56.2"},
+		{"Result", "ok", "ng", "ok"},
+	}
+	tests := []struct {
+		policy      RaggedRowPolicy
+		vertical    bool
+		endRow      int
+		expected    []map[string]any
+		tableReport TableReport
+		expectFail  bool
+	}{
+		{
+			policy:     RaggedRowsError,
+			expectFail: true,
+		},
+		{
+			policy: RaggedRowsPad,
+			expected: []map[string]any{
+				{"Shot": int64(1), "Cycle": Float64(55.3), "Result": true},
+				{"Shot": int64(2), "Cycle": Float64(56.2), "Result": nil},
+				{"Shot": int64(3), "Cycle": nil, "Result": nil},
+			},
+			tableReport: TableReport{Name: "shots", PaddedRows: 2},
+		},
+		{
+			policy: RaggedRowsSkip,
+			expected: []map[string]any{
+				{"Shot": int64(1), "Cycle": Float64(55.3), "Result": true},
+			},
+			tableReport: TableReport{Name: "shots", SkippedRows: 2},
+		},
+		{
+			policy: RaggedRowsTruncate,
+			expected: []map[string]any{
+				{"Shot": int64(1), "Cycle": Float64(55.3), "Result": true},
+				{"Shot": int64(2), "Cycle": Float64(56.2)},
+				{"Shot": int64(3)},
+			},
+			tableReport: TableReport{Name: "shots", TruncatedRows: 2},
+		},
+		{
+			policy:     RaggedRowsError,
+			vertical:   true,
+			expectFail: true,
+		},
+		{
+			policy:   RaggedRowsPad,
+			vertical: true,
+			expected: []map[string]any{
+				{"Shot": int64(1), "Cycle": Float64(55.3), "Result": true},
+				{"Shot": int64(2), "Cycle": Float64(56.2), "Result": false},
+				{"Shot": int64(3), "Cycle": nil, "Result": true},
+			},
+			tableReport: TableReport{Name: "shots", PaddedRows: 1},
+		},
+		{
+			policy:   RaggedRowsSkip,
+			vertical: true,
+			expected: []map[string]any{
+				{"Shot": int64(1), "Cycle": Float64(55.3), "Result": true},
+				{"Shot": int64(2), "Cycle": Float64(56.2), "Result": false},
+			},
+			tableReport: TableReport{Name: "shots", SkippedRows: 1},
+		},
+		{
+			policy:   RaggedRowsTruncate,
+			vertical: true,
+			expected: []map[string]any{
+				{"Shot": int64(1), "Cycle": Float64(55.3), "Result": true},
+				{"Shot": int64(2), "Cycle": Float64(56.2), "Result": false},
+				{"Shot": int64(3), "Result": true},
+			},
+			tableReport: TableReport{Name: "shots", TruncatedRows: 1},
+		},
+		{
+			policy:     RaggedRowsError,
+			vertical:   true,
+			endRow:     3,
+			expectFail: true,
+		},
+		{
+			policy:   RaggedRowsPad,
+			vertical: true,
+			endRow:   3,
+			expected: []map[string]any{
+				{"Shot": int64(1), "Cycle": Float64(55.3), "Result": true, "": nil},
+				{"Shot": int64(2), "Cycle": Float64(56.2), "Result": false, "": nil},
+				{"Shot": int64(3), "Cycle": nil, "Result": true, "": nil},
+			},
+			tableReport: TableReport{Name: "shots", PaddedRows: 3},
+		},
+	}
+
+	for n, test := range tests {
+		table := TableLocation{
+			Name:            "shots",
+			EndCell:         Cell{Row: -1, Column: -1},
+			TableHasHeader:  true,
+			ColumnDataTypes: []DataType{DataTypeInt64, DataTypeFloat64, DataTypeBool},
+			RaggedRows:      test.policy,
+		}
+		tableRecords := records
+		if test.vertical {
+			table.Orientation = TableOrientationVertical
+			tableRecords = verticalRecords
+		}
+		if test.endRow > 0 {
+			table.EndCell.Row = test.endRow
+			table.ColumnDataTypes = append(table.ColumnDataTypes, DataTypeString)
+		}
+		csv := Csv{TableLocations: []TableLocation{table}}
+
+		data, report, err := csv.ParseRecordsWithReport(tableRecords)
+		if err != nil {
+			if !test.expectFail {
+				t.Errorf("Test %d: error parsing records: %v", n, err)
+			}
+			continue
+		} else if test.expectFail {
+			t.Errorf("Test %d: expected error", n)
+			continue
+		}
+
+		if !reflect.DeepEqual(data.(map[string]any)["shots"], test.expected) {
+			t.Errorf("Test %d: table does not match\nexpected: %v\nreceived: %v", n, test.expected, data.(map[string]any)["shots"])
+		}
+		if len(report.Tables) != 1 || report.Tables[0] != test.tableReport {
+			t.Errorf("Test %d: report does not match\nexpected: %v\nreceived: %v", n, test.tableReport, report.Tables)
+		}
+	}
+}
+
+func TestTableColumnTypesByHeader(t *testing.T) {
+	t.Parallel()
+
+	records := [][]string{
+		{"Machine", "Shot", "Alarm1", "Alarm2", "Cycle Time", "Result"},
+		{"DCM 16", "1", "0", "1", "55.3", "ok"},
+		{"DCM 16", "2", "2", "0", "56.2", "ng"},
+	}
+	table := TableLocation{
+		Name:           "shots",
+		StartCell:      Cell{Row: 0, Column: 1},
+		EndCell:        Cell{Row: -1, Column: -1},
+		TableHasHeader: true,
+		ColumnTypesByHeader: map[string]DataType{
+			"Shot":       DataTypeString,
+			"Alarm*":     DataTypeInt64,
+			"/^Cycle_/":  DataTypeFloat64,
+			"/^Alarm1$/": DataTypeFloat64,
+		},
+		DefaultColumnDataType: DataTypeBool,
+	}
+
+	_, data, err := table.Parse(records, false)
+	if err != nil {
+		t.Fatalf("failed to parse table: %v", err)
+	}
+	expected := []map[string]any{
+		{"Shot": "1", "Alarm1": Float64(0), "Alarm2": int64(1), "Cycle_Time": Float64(55.3), "Result": true},
+		{"Shot": "2", "Alarm1": Float64(2), "Alarm2": int64(0), "Cycle_Time": Float64(56.2), "Result": false},
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("table does not match\nexpected: %v\nreceived: %v", expected, data)
+	}
+
+	single := table
+	single.ParseSingleRow = true
+	_, data, err = single.Parse(records, false)
+	if err != nil {
+		t.Fatalf("failed to parse single row table: %v", err)
+	}
+	if !reflect.DeepEqual(data, expected[0]) {
+		t.Errorf("single row does not match\nexpected: %v\nreceived: %v", expected[0], data)
+	}
+
+	array := table
+	array.ParseAsArray = true
+	_, data, err = array.Parse(records, false)
+	if err != nil {
+		t.Fatalf("failed to parse array table: %v", err)
+	}
+	expectedArray := map[string][]any{
+		"Shot":       {"1", "2"},
+		"Alarm1":     {Float64(0), Float64(2)},
+		"Alarm2":     {int64(1), int64(0)},
+		"Cycle_Time": {Float64(55.3), Float64(56.2)},
+		"Result":     {true, false},
+	}
+	if !reflect.DeepEqual(data, expectedArray) {
+		t.Errorf("array does not match\nexpected: %v\nreceived: %v", expectedArray, data)
+	}
+
+	invalid := table
+	invalid.ColumnTypesByHeader = map[string]DataType{"/[/": DataTypeInt64}
+	if _, _, err = invalid.Parse(records, false); err == nil {
+		t.Errorf("expected error for invalid regex")
+	}
+}
+
+func TestTableInferColumnDataTypes(t *testing.T) {
+	t.Parallel()
+
+	records := [][]string{
+		{"Shot", "Cycle", "Result", "Date", "Note", "Code"},
+		{"1", "55", "ok", "2024-09-23 08:04:18", "first", "A1"},
+		{"2", "56.2", "true", "2024-09-23 08:05:14", "", "7"},
+		{"3", "", "ng", "2024-09-23 08:06:10", "last", "8"},
+		{"4", "57.1", "false", "2024-09-23 08:07:06", "", "x"},
+	}
+	table := TableLocation{
+		Name:                 "shots",
+		EndCell:              Cell{Row: -1, Column: -1},
+		TableHasHeader:       true,
+		InferColumnDataTypes: true,
+		InferenceThreshold:   0.5,
+		SkipBlankData:        true,
+	}
+
+	var report Report
+	_, data, err := table.parse(records, false, nil, &report)
+	if err != nil {
+		t.Fatalf("failed to parse table: %v", err)
+	}
+	rows := data.([]map[string]any)
+	if rows[0]["Shot"] != int64(1) {
+		t.Errorf("Shot should be int64 but is %v of type %T", rows[0]["Shot"], rows[0]["Shot"])
+	}
+	if rows[0]["Cycle"] != Float64(55) {
+		t.Errorf("Cycle should be Float64 but is %v of type %T", rows[0]["Cycle"], rows[0]["Cycle"])
+	}
+	if rows[0]["Result"] != true || rows[2]["Result"] != false {
+		t.Errorf("Result should be bool but is %v of type %T", rows[0]["Result"], rows[0]["Result"])
+	}
+	if _, ok := rows[0]["Date"].(string); !ok || rows[0]["Date"] == "2024-09-23 08:04:18" {
+		t.Errorf("Date should be a formatted datetime but is %v", rows[0]["Date"])
+	}
+	if rows[0]["Note"] != "first" {
+		t.Errorf("Note should be a string but is %v of type %T", rows[0]["Note"], rows[0]["Note"])
+	}
+	if _, exists := rows[3]["Code"]; exists || rows[1]["Code"] != int64(7) {
+		t.Errorf("Code should be int64 with the mismatched values removed but is %v and %v", rows[1]["Code"], rows[3]["Code"])
+	}
+	if len(report.Warnings) != 2 {
+		t.Fatalf("expected 2 warnings instead of %d: %v", len(report.Warnings), report.Warnings)
+	}
+	if report.Warnings[0].Raw != "A1" || report.Warnings[0].Row != 1 || report.Warnings[1].Raw != "x" {
+		t.Errorf("warnings do not match the mismatched values: %v", report.Warnings)
+	}
+
+	table.InferenceThreshold = 1
+	report = Report{}
+	_, data, err = table.parse(records, false, nil, &report)
+	if err != nil {
+		t.Fatalf("failed to parse table: %v", err)
+	}
+	if rows := data.([]map[string]any); rows[1]["Code"] != "7" {
+		t.Errorf("Code should fall back to string but is %v of type %T", rows[1]["Code"], rows[1]["Code"])
+	}
+	if len(report.Warnings) != 1 || report.Warnings[0].Header != "Code" || report.Warnings[0].Row != -1 {
+		t.Errorf("expected a single column warning for Code: %v", report.Warnings)
+	}
+
+	mixed := [][]string{{"Value"}, {"1"}, {"2"}, {"3.5"}, {"4"}, {"5.5"}}
+	table.InferenceThreshold = 0.5
+	report = Report{}
+	_, data, err = table.parse(mixed, false, nil, &report)
+	if err != nil {
+		t.Fatalf("failed to parse table: %v", err)
+	}
+	rows = data.([]map[string]any)
+	if rows[0]["Value"] != Float64(1) || rows[2]["Value"] != Float64(3.5) || rows[4]["Value"] != Float64(5.5) {
+		t.Errorf("Value should be Float64 but is %v, %v and %v", rows[0]["Value"], rows[2]["Value"], rows[4]["Value"])
+	}
+	if len(report.Warnings) != 0 {
+		t.Errorf("expected no warnings for a mixed int and float column: %v", report.Warnings)
+	}
+}
+
+func TestLocation(t *testing.T) {
+	t.Parallel()
+
+	records := [][]string{
+		{"start", "2024-09-10 12:53:09"},
+		{"stamp", "240910T12:53:09"},
+		{"Time", "Value"},
+		{"2024-09-10 12:53:09", "1"},
+	}
+	csv := Csv{
+		Options: &DataTypeOptions{Location: "Asia/Tokyo"},
+		CellLocations: []CellLocation{
+			{Name: "start", Location: Cell{Row: 0, Column: 1}, DataType: DataTypeDateTimeStyle0},
+		},
+		TimeFields: []TimeField{
+			{Name: "@timestamp", Cells: []Cell{{Row: 1, Column: 1}}, Layout: "060102T15:04:05", Location: "UTC"},
+		},
+		TableLocations: []TableLocation{
+			{
+				Name:                "values",
+				StartCell:           Cell{Row: 2, Column: 0},
+				EndCell:             Cell{Row: -1, Column: -1},
+				TableHasHeader:      true,
+				ParseSingleRow:      true,
+				ColumnTypesByHeader: map[string]DataType{"Time": DataTypeDateTimeStyle0},
+				ColumnOptions:       map[string]*DataTypeOptions{"Time": {Location: "America/New_York"}},
+			},
+		},
+	}
+
+	data, err := csv.ParseRecords(records)
+	if err != nil {
+		t.Fatalf("error parsing records: %v", err)
+	}
+	output := data.(map[string]any)
+	if output["start"] != "2024-09-10T12:53:09+09:00" {
+		t.Errorf("start should be in Asia/Tokyo but is %v", output["start"])
+	}
+	if output["@timestamp"] != "2024-09-10T12:53:09Z" {
+		t.Errorf("@timestamp should be in UTC but is %v", output["@timestamp"])
+	}
+	if value := output["values"].(map[string]any)["Time"]; value != "2024-09-10T12:53:09-04:00" {
+		t.Errorf("Time should be in America/New_York but is %v", value)
+	}
+
+	csv.LocationFromFilePath = "plant"
+	csv.FilePathLocations = map[string]string{"P1": "Europe/Berlin"}
+	options, err := csv.fileOptions(map[string]string{"plant": "P1"})
+	if err != nil {
+		t.Fatalf("error finding file options: %v", err)
+	} else if options.Location != "Europe/Berlin" {
+		t.Errorf("location should be Europe/Berlin but is %s", options.Location)
+	}
+	if _, err = csv.fileOptions(map[string]string{"plant": "P2"}); err == nil {
+		t.Errorf("expected error for plant without a location")
+	}
+}
+
+func TestAmbiguousTime(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value      string
+		layout     string
+		policy     AmbiguousTimePolicy
+		expected   string
+		expectFail bool
+	}{
+		{value: "2024-11-03 01:30:00", policy: AmbiguousTimeEarliest, expected: "2024-11-03T01:30:00-05:00"},
+		{value: "2024-11-03 01:30:00", policy: AmbiguousTimeLatest, expected: "2024-11-03T01:30:00-06:00"},
+		{value: "2024-11-03 01:30:00", policy: AmbiguousTimeError, expectFail: true},
+		{value: "2024-03-10 02:30:00", policy: AmbiguousTimeEarliest, expected: "2024-03-10T01:30:00-06:00"},
+		{value: "2024-03-10 02:30:00", policy: AmbiguousTimeLatest, expected: "2024-03-10T03:30:00-05:00"},
+		{value: "2024-03-10 02:30:00", policy: AmbiguousTimeError, expectFail: true},
+		{value: "2024-07-01 12:00:00", policy: AmbiguousTimeError, expected: "2024-07-01T12:00:00-05:00"},
+		{value: "2024-07-01T12:00:00-05:00", layout: time.RFC3339, policy: AmbiguousTimeEarliest, expected: "2024-07-01T12:00:00-05:00"},
+		{value: "2024-07-01T12:00:00-04:00", layout: time.RFC3339, policy: AmbiguousTimeLatest, expected: "2024-07-01T12:00:00-04:00"},
+		{value: "2024-11-03T01:30:00-06:00", layout: time.RFC3339, policy: AmbiguousTimeError, expected: "2024-11-03T01:30:00-06:00"},
+	}
+
+	for n, test := range tests {
+		dataType, options := DataTypeDateTimeStyle0, &DataTypeOptions{Location: "America/Chicago", AmbiguousTime: test.policy}
+		if test.layout != "" {
+			dataType, options.Layouts = DataTypeDateTime, []string{test.layout}
+		}
+		data, err := dataType.ReadWithOptions(test.value, options)
+		if err != nil {
+			if !test.expectFail {
+				t.Errorf("Test %d: error reading value: %v", n, err)
+			}
+			continue
+		} else if test.expectFail {
+			t.Errorf("Test %d: expected error but received %v", n, data)
+			continue
+		}
+		if data != test.expected {
+			t.Errorf("Test %d: expected %s but received %v", n, test.expected, data)
+		}
+	}
+}
+
+func TestTimeFormat(t *testing.T) {
+	t.Parallel()
+
+	records := [][]string{
+		{"stamp", "2024-09-23 08:04:18.250"},
+		{"Time", "Epoch"},
+		{"2024/09/23 08:04:18", "1727078658250"},
+	}
+	tests := []struct {
+		options  DataTypeOptions
+		expected map[string]any
+	}{
+		{
+			options: DataTypeOptions{Location: "Asia/Tokyo"},
+			expected: map[string]any{
+				"@timestamp": "2024-09-23T08:04:18+09:00",
+				"shots":      map[string]any{"Time": "2024-09-23T08:04:18+09:00", "Epoch": "2024-09-23T17:04:18+09:00"},
+			},
+		},
+		{
+			options: DataTypeOptions{Location: "Asia/Tokyo", TimeFormat: TimeFormatRFC3339Nano, TimeUTC: ptr(true)},
+			expected: map[string]any{
+				"@timestamp": "2024-09-22T23:04:18.25Z",
+				"shots":      map[string]any{"Time": "2024-09-22T23:04:18Z", "Epoch": "2024-09-23T08:04:18.25Z"},
+			},
+		},
+		{
+			options: DataTypeOptions{Location: "UTC", TimeFormat: TimeFormatEpochMillis},
+			expected: map[string]any{
+				"@timestamp": int64(1727078658250),
+				"shots":      map[string]any{"Time": int64(1727078658000), "Epoch": int64(1727078658250)},
+			},
+		},
+		{
+			options: DataTypeOptions{Location: "UTC", TimeFormat: "%d.%m.%Y %H:%M"},
+			expected: map[string]any{
+				"@timestamp": "23.09.2024 08:04",
+				"shots":      map[string]any{"Time": "23.09.2024 08:04", "Epoch": "23.09.2024 08:04"},
+			},
+		},
+	}
+
+	for n, test := range tests {
+		csv := Csv{
+			Options: &test.options,
+			TimeFields: []TimeField{
+				{Name: "@timestamp", Cells: []Cell{{Row: 0, Column: 1}}, Layout: "2006-01-02 15:04:05.000"},
+			},
+			TableLocations: []TableLocation{
+				{
+					Name:            "shots",
+					StartCell:       Cell{Row: 1, Column: 0},
+					EndCell:         Cell{Row: -1, Column: -1},
+					TableHasHeader:  true,
+					ParseSingleRow:  true,
+					ColumnDataTypes: []DataType{DataTypeDateTimeStyle1, DataTypeEpoch},
+					ColumnOptions:   map[string]*DataTypeOptions{"Epoch": {TimeUnit: "ms"}},
+				},
+			},
+		}
+
+		data, err := csv.ParseRecords(records)
+		if err != nil {
+			t.Errorf("Test %d: error parsing records: %v", n, err)
+		} else if !reflect.DeepEqual(data, test.expected) {
+			t.Errorf("Test %d: output does not match\nexpected: %v\nreceived: %v", n, test.expected, data)
+		}
+	}
+}
+
+func TestNullValues(t *testing.T) {
+	t.Parallel()
+
+	records := [][]string{
+		{"Operator", "N/A"},
+		{"Shot", "Press.-Up Time", "Fill-Completion Pos.", "Note"},
+		{"989301", "-1", "-0.1", "---"},
+		{"989302", "46", "187.6", " N/A "},
+	}
+	csv := Csv{
+		Options: &DataTypeOptions{NullValues: []string{"N/A", "---"}},
+		CellLocations: []CellLocation{
+			{NameCell: Cell{Row: 0, Column: 0}, Location: Cell{Row: 0, Column: 1}, DataType: DataTypeString},
+		},
+		TableLocations: []TableLocation{
+			{
+				Name:                  "shot",
+				StartCell:             Cell{Row: 1, Column: 0},
+				EndCell:               Cell{Row: -1, Column: -1},
+				TableHasHeader:        true,
+				ColumnTypesByHeader:   map[string]DataType{"Shot": DataTypeInt64, "Note": DataTypeString},
+				DefaultColumnDataType: DataTypeFloat64,
+				SkipBlankData:         true,
+				ColumnOptions: map[string]*DataTypeOptions{
+					"Press.-Up_Time":       {NullValues: []string{"-1"}},
+					"Fill-Completion_Pos.": {NullValues: []string{"-0.1"}},
+				},
+			},
+		},
+	}
+
+	data, err := csv.ParseRecords(records)
+	if err != nil {
+		t.Fatalf("error parsing records: %v", err)
+	}
+	expected := map[string]any{
+		"Operator": nil,
+		"shot": []map[string]any{
+			{"Shot": int64(989301)},
+			{"Shot": int64(989302), "Press.-Up_Time": Float64(46), "Fill-Completion_Pos.": Float64(187.6)},
+		},
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("output does not match\nexpected: %v\nreceived: %v", expected, data)
+	}
+}
+
+func TestValueMapping(t *testing.T) {
+	t.Parallel()
+
+	reference := filepath.Join(t.TempDir(), "states.csv")
+	err := os.WriteFile(reference, []byte("code,label\n0,idle\n2,cycle\n7,alarm\n"), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write reference file: %v", err)
+	}
+
+	records := [][]string{
+		{"State", "2"},
+		{"Time", "State", "Fault"},
+		{"1", "0", "E1"},
+		{"2", "7", "E9"},
+		{"3", "5", ""},
+	}
+	c := Csv{
+		CellLocations: []CellLocation{{
+			Location: Cell{Row: 0, Column: 1},
+			NameCell: Cell{Row: 0, Column: 0},
+			DataType: DataTypeInt64,
+			Options:  &DataTypeOptions{Mapping: &ValueMapping{File: reference, FileHasHeader: true, Unmapped: UnmappedError}},
+		}},
+		TableLocations: []TableLocation{{
+			Name:                "states",
+			StartCell:           Cell{Row: 1, Column: 0},
+			EndCell:             Cell{Row: -1, Column: -1},
+			TableHasHeader:      true,
+			ColumnTypesByHeader: map[string]DataType{"Time": DataTypeInt64, "State": DataTypeInt64, "Fault": DataTypeString},
+			ColumnOptions: map[string]*DataTypeOptions{
+				"State": {Mapping: &ValueMapping{Values: map[string]string{"5": "setup"}, File: reference, FileHasHeader: true}},
+				"Fault": {Mapping: &ValueMapping{Values: map[string]string{"E1": "door open"}, Unmapped: UnmappedNull}},
+			},
+		}},
+	}
+
+	data, err := c.ParseRecords(records)
+	if err != nil {
+		t.Fatalf("failed to parse records: %v", err)
+	}
+	expected := map[string]any{
+		"State": "cycle",
+		"states": []map[string]any{
+			{"Time": int64(1), "State": "idle", "Fault": "door open"},
+			{"Time": int64(2), "State": "alarm", "Fault": nil},
+			{"Time": int64(3), "State": "setup", "Fault": nil},
+		},
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("records do not match\nexpected: %v\nreceived: %v", expected, data)
+	}
+
+	keep := c
+	keep.CellLocations = nil
+	keep.TableLocations = []TableLocation{c.TableLocations[0]}
+	keep.TableLocations[0].ColumnOptions = map[string]*DataTypeOptions{
+		"State": {Mapping: &ValueMapping{File: reference, FileHasHeader: true}},
+	}
+	data, err = keep.ParseRecords(records)
+	if err != nil {
+		t.Fatalf("failed to parse records keeping unmapped values: %v", err)
+	}
+	if state := data.(map[string]any)["states"].([]map[string]any)[2]["State"]; state != int64(5) {
+		t.Errorf("expected unmapped value to be kept as 5 but received %v of type %T", state, state)
+	}
+
+	records[0][1] = "3"
+	if _, err = c.ParseRecords(records); err == nil {
+		t.Errorf("expected error for unmapped cell value")
+	}
+
+	// edits to the reference file are picked up without a restart
+	err = os.WriteFile(reference, []byte("code,label\n0,idle\n2,cycle\n3,setup\n7,alarm\n"), 0o644)
+	if err != nil {
+		t.Fatalf("failed to update reference file: %v", err)
+	}
+	modified := time.Now().Add(time.Minute)
+	if err = os.Chtimes(reference, modified, modified); err != nil {
+		t.Fatalf("failed to update modification time of reference file: %v", err)
+	}
+	cached, _ := mappingFiles.Load(mappingFile{path: reference, hasHeader: true})
+	cached.(*mappingLabels).checked.Store(time.Now().UnixNano())
+	if _, err = c.ParseRecords(records); err == nil {
+		t.Errorf("expected the cached labels to be used until the next check for changes")
+	}
+
+	// expire the last check instead of waiting for mappingCheckInterval
+	cached.(*mappingLabels).checked.Store(0)
+	data, err = c.ParseRecords(records)
+	if err != nil {
+		t.Fatalf("failed to parse records after updating reference file: %v", err)
+	}
+	if state := data.(map[string]any)["State"]; state != "setup" {
+		t.Errorf("expected updated label setup but received %v", state)
+	}
+}
+
+func TestTableUnitConversion(t *testing.T) {
+	t.Parallel()
+
+	one := 1
+	records := [][]string{
+		{"Time", "Temp", "Pres", "Pos"},
+		{"s", "°F", "[psi]", "counts"},
+		{"0", "212", "145.0377", "1000"},
+		{"1", "32", "0", "2500"},
+	}
+	table := TableLocation{
+		Name:                "readings",
+		StartCell:           Cell{Row: 0, Column: 0},
+		EndCell:             Cell{Row: -1, Column: -1},
+		TableHasHeader:      true,
+		UnitRow:             true,
+		ColumnTypesByHeader: map[string]DataType{"*": DataTypeFloat64},
+		Options:             &DataTypeOptions{RoundDigits: &one},
+		ColumnOptions: map[string]*DataTypeOptions{
+			"Temp": {ToUnit: "°C"},
+			"Pres": {ToUnit: "MPa"},
+			"Pos":  {Scale: ptr(0.01), FromUnit: "mm", ToUnit: "in"},
+		},
+		Unpivot: &Unpivot{KeyColumns: []string{"Time"}},
+	}
+
+	_, data, err := table.Parse(records, false)
+	if err != nil {
+		t.Fatalf("failed to parse table: %v", err)
+	}
+	expected := []map[string]any{
+		{"Time": Float64(0), "metric": "Temp", "value": Float64(100), "unit": "°C"},
+		{"Time": Float64(0), "metric": "Pres", "value": Float64(1), "unit": "MPa"},
+		{"Time": Float64(0), "metric": "Pos", "value": Float64(0.4), "unit": "in"},
+		{"Time": Float64(1), "metric": "Temp", "value": Float64(0), "unit": "°C"},
+		{"Time": Float64(1), "metric": "Pres", "value": Float64(0), "unit": "MPa"},
+		{"Time": Float64(1), "metric": "Pos", "value": Float64(1), "unit": "in"},
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("table does not match\nexpected: %v\nreceived: %v", expected, data)
+	}
+}
+
+func TestColumnOptionsOverride(t *testing.T) {
+	t.Parallel()
+
+	records := [][]string{
+		{"Yield", "Scrap", "Temp", "Count"},
+		{"93%", "7%", "20", "4"},
+	}
+	csv := Csv{
+		Options: &DataTypeOptions{PercentToFraction: ptr(true), Scale: ptr(2.0), Offset: ptr(1.0), KeepIntegers: ptr(true)},
+		TableLocations: []TableLocation{
+			{
+				Name:                "shots",
+				EndCell:             Cell{Row: -1, Column: -1},
+				TableHasHeader:      true,
+				ColumnTypesByHeader: map[string]DataType{"*": DataTypeAuto},
+				ColumnOptions: map[string]*DataTypeOptions{
+					"Yield": {Scale: ptr(1.0), Offset: ptr(0.0)},
+					"Scrap": {PercentToFraction: ptr(false), StripPattern: "%$", Scale: ptr(1.0), Offset: ptr(0.0)},
+					"Count": {KeepIntegers: ptr(false)},
+				},
+			},
+		},
+	}
+
+	data, err := csv.ParseRecords(records)
+	if err != nil {
+		t.Fatalf("failed to parse records: %v", err)
+	}
+	expected := []map[string]any{
+		{"Yield": Float64(0.93), "Scrap": int64(7), "Temp": int64(41), "Count": Float64(9)},
+	}
+	if !reflect.DeepEqual(data.(map[string]any)["shots"], expected) {
+		t.Errorf("table does not match\nexpected: %v\nreceived: %v", expected, data.(map[string]any)["shots"])
+	}
+}
+
+func TestRawOutput(t *testing.T) {
+	t.Parallel()
+
+	records := [][]string{
+		{"Shot", "0042"},
+		{"Temperature", "Result"},
+		{"4.80", "ok"},
+		{"5,1", "ng"},
+	}
+	c := Csv{
+		Options: &DataTypeOptions{RawOutput: RawOutputSuffix},
+		CellLocations: []CellLocation{
+			{NameCell: Cell{Row: 0, Column: 0}, Location: Cell{Row: 0, Column: 1}, DataType: DataTypeAuto},
+		},
+		TableLocations: []TableLocation{{
+			Name:                "shots",
+			StartCell:           Cell{Row: 1, Column: 0},
+			EndCell:             Cell{Row: -1, Column: -1},
+			TableHasHeader:      true,
+			AutoColumnDataTypes: true,
+			ColumnOptions: map[string]*DataTypeOptions{
+				"Temperature": {DecimalSeparator: ","},
+				"Result":      {RawOutput: RawOutputObject},
+			},
+		}},
+	}
+
+	data, err := c.ParseRecords(records)
+	if err != nil {
+		t.Fatalf("failed to parse records: %v", err)
+	}
+	expected := map[string]any{
+		"Shot":     Float64(42),
+		"Shot_raw": "0042",
+		"shots": []map[string]any{
+			{"Temperature": Float64(4.8), "Temperature_raw": "4.80", "Result": map[string]any{"value": true, "raw": "ok"}},
+			{"Temperature": Float64(5.1), "Temperature_raw": "5,1", "Result": map[string]any{"value": false, "raw": "ng"}},
+		},
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("records do not match\nexpected: %v\nreceived: %v", expected, data)
+	}
+
+	array := c
+	array.CellLocations = nil
+	array.TableLocations = []TableLocation{c.TableLocations[0]}
+	array.TableLocations[0].ParseAsArray = true
+	array.TableLocations[0].ColumnOptions = map[string]*DataTypeOptions{"Temperature": {DecimalSeparator: ",", RawSuffix: ".source"}}
+	data, err = array.ParseRecords(records)
+	if err != nil {
+		t.Fatalf("failed to parse array records: %v", err)
+	}
+	expectedArray := map[string]any{
+		"shots": map[string][]any{
+			"Temperature":        {Float64(4.8), Float64(5.1)},
+			"Temperature.source": {"4.80", "5,1"},
+			"Result":             {true, false},
+			"Result_raw":         {"ok", "ng"},
+		},
+	}
+	if !reflect.DeepEqual(data, expectedArray) {
+		t.Errorf("array records do not match\nexpected: %v\nreceived: %v", expectedArray, data)
+	}
+
+	c.Options.RawOutput = "both"
+	if _, err = c.ParseRecords(records); err == nil {
+		t.Errorf("expected error for invalid raw output")
+	}
+}
+
+func TestErrorPolicy(t *testing.T) {
+	t.Parallel()
+
+	records := [][]string{
+		{"Shot", "A1"},
+		{"Time", "Count", "Temp", "Code"},
+		{"1", "10", "4.5", "7"},
+		{"2", "x", "4.6", "8"},
+		{"3", "12", "bad", "9"},
+		{"4", "13", "4.8", "E1"},
+	}
+	c := Csv{
+		Options: &DataTypeOptions{OnError: ErrorPolicyNull},
+		CellLocations: []CellLocation{
+			{NameCell: Cell{Row: 0, Column: 0}, Location: Cell{Row: 0, Column: 1}, DataType: DataTypeInt64},
+		},
+		TableLocations: []TableLocation{{
+			Name:                "shots",
+			StartCell:           Cell{Row: 1, Column: 0},
+			EndCell:             Cell{Row: -1, Column: -1},
+			TableHasHeader:      true,
+			ColumnTypesByHeader: map[string]DataType{"Temp": DataTypeFloat64, "*": DataTypeInt64},
+			Options:             &DataTypeOptions{OnError: ErrorPolicyString},
+			ColumnOptions: map[string]*DataTypeOptions{
+				"Temp": {OnError: ErrorPolicyDropRow},
+				"Code": {OnError: ErrorPolicyNull},
+			},
+		}},
+	}
+
+	data, report, err := c.ParseRecordsWithReport(records)
+	if err != nil {
+		t.Fatalf("failed to parse records: %v", err)
+	}
+	expected := map[string]any{
+		"Shot": nil,
+		"shots": []map[string]any{
+			{"Time": int64(1), "Count": int64(10), "Temp": Float64(4.5), "Code": int64(7)},
+			{"Time": int64(2), "Count": "x", "Temp": Float64(4.6), "Code": int64(8)},
+			{"Time": int64(4), "Count": int64(13), "Temp": Float64(4.8), "Code": nil},
+		},
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("records do not match\nexpected: %v\nreceived: %v", expected, data)
+	}
+
+	expectedWarnings := []struct {
+		table  string
+		row    int
+		column int
+		header string
+		raw    string
+	}{
+		{table: "", row: 0, column: 1, header: "Shot", raw: "A1"},
+		{table: "shots", row: 4, column: 2, header: "Temp", raw: "bad"},
+		{table: "shots", row: 3, column: 1, header: "Count", raw: "x"},
+		{table: "shots", row: 5, column: 3, header: "Code", raw: "E1"},
+	}
+	if len(report.Warnings) != len(expectedWarnings) {
+		t.Fatalf("expected %d warnings instead of %d: %v", len(expectedWarnings), len(report.Warnings), report.Warnings)
+	}
+	for n, expected := range expectedWarnings {
+		warning := report.Warnings[n]
+		if warning.Table != expected.table || warning.Row != expected.row || warning.Column != expected.column ||
+			warning.Header != expected.header || warning.Raw != expected.raw || warning.Reason == "" {
+			t.Errorf("Warning %d: expected %+v but received %+v", n, expected, warning)
+		}
+	}
+	if len(report.Tables) != 1 || report.Tables[0].DroppedRows != 1 {
+		t.Errorf("expected 1 dropped row: %+v", report.Tables)
+	}
+
+	c.Options = nil
+	if _, err = c.ParseRecords(records); err == nil {
+		t.Errorf("expected error for failed cell conversion")
+	}
+
+	c.Options = &DataTypeOptions{OnError: "skip"}
+	if _, err = c.ParseRecords(records); err == nil {
+		t.Errorf("expected error for invalid error policy")
+	}
+}
+
+func TestUnmarshalLegacyIntegers(t *testing.T) {
+	t.Parallel()
+
+	legacy := `{
+		"PreProcessor": [{"Type": 2, "Name": "fill", "Start": {"Row": 0, "Column": 0}, "End": {"Row": -1, "Column": -1}}],
+		"CellLocations": [{"Name": "speed", "Location": {"Row": 0, "Column": 1}, "DataType": 4}],
+		"TableLocations": [{"Name": "metrics", "ColumnDataTypes": [3, 2, 6]}]
+	}`
+	current := `{
+		"PreProcessor": [{"Type": "fillRight", "Name": "fill", "Start": {"Row": 0, "Column": 0}, "End": {"Row": -1, "Column": -1}}],
+		"CellLocations": [{"Name": "speed", "Location": {"Row": 0, "Column": 1}, "DataType": "float64"}],
+		"TableLocations": [{"Name": "metrics", "ColumnDataTypes": ["int64", "string", "dateTimeStyle0"]}]
+	}`
+
+	var fromLegacy, fromCurrent Csv
+	if err := json.Unmarshal([]byte(legacy), &fromLegacy); err != nil {
+		t.Fatalf("error unmarshaling legacy config: %v", err)
+	}
+	if err := json.Unmarshal([]byte(current), &fromCurrent); err != nil {
+		t.Fatalf("error unmarshaling config: %v", err)
+	}
+	if !reflect.DeepEqual(fromLegacy, fromCurrent) {
+		t.Errorf("legacy config does not match\nexpected: %v\nreceived: %v", fromCurrent, fromLegacy)
+	}
+	if fromCurrent.PreProcessor[0].GetType() != ProcessorTypeFillRight || fromCurrent.CellLocations[0].DataType != DataTypeFloat64 {
+		t.Errorf("unexpected types in config: %v", fromCurrent)
+	}
+
+	marshaled, err := json.Marshal(fromLegacy)
+	if err != nil {
+		t.Fatalf("error marshaling config: %v", err)
+	}
+	for _, name := range []string{`"Type":"fillRight"`, `"DataType":"float64"`, `"ColumnDataTypes":["int64","string","dateTimeStyle0"]`} {
+		if !strings.Contains(string(marshaled), name) {
+			t.Errorf("marshaled config does not contain %s: %s", name, marshaled)
+		}
+	}
+
+	if err = json.Unmarshal([]byte(`{"PreProcessor": [{"Type": "fillLeft"}]}`), &fromCurrent); err == nil {
+		t.Errorf("expected error for unknown processor type")
+	}
+}
+
+func TestProcessorReport(t *testing.T) {
+	t.Parallel()
+
+	filePath := filepath.Join(t.TempDir(), "readings.csv")
+	err := os.WriteFile(filePath, []byte("Time,,Temp\ns,,°C\n0,,21.5\n,,\n1,,21.7\nTotal,,43.2\n"), 0o644)
+	if err != nil {
+		t.Fatalf("failed to write file: %v", err)
+	}
+
+	c := Csv{
+		PreProcessor: []Processor{
+			&ProcessorDeleteRows{Name: "units", StartRow: 1, EndRow: 2},
+			&ProcessorDeleteColumns{Name: "blank columns", EndColumn: -1, Blank: true},
+			&ProcessorFillRight{Name: "fill", End: Cell{Row: -1, Column: -1}},
+			&ProcessorDeleteRows{Name: "summary", StartRow: 1, EndRow: -1, Blank: true, Pattern: "^Total$"},
+		},
+		TableLocations: []TableLocation{{
+			Name:                "readings",
+			StartCell:           Cell{Row: 0, Column: 0},
+			EndCell:             Cell{Row: -1, Column: -1},
+			TableHasHeader:      true,
+			AutoColumnDataTypes: true,
+		}},
+	}
+
+	data, _, report, err := c.ParseFileWithReport(filePath)
+	if err != nil {
+		t.Fatalf("failed to parse file: %v", err)
+	}
+	expectedData := []map[string]any{{"readings": []map[string]any{
+		{"Time": Float64(0), "Temp": Float64(21.5)},
+		{"Time": Float64(1), "Temp": Float64(21.7)},
+	}}}
+	if !reflect.DeepEqual(data, expectedData) {
+		t.Errorf("data does not match\nexpected: %v\nreceived: %v", expectedData, data)
+	}
+
+	expected := []ProcessorReport{
+		{Name: "units", Type: ProcessorTypeDeleteRows, Removed: 1},
+		{Name: "blank columns", Type: ProcessorTypeDeleteColumns, Removed: 1},
+		{Name: "summary", Type: ProcessorTypeDeleteRows, Removed: 2},
+	}
+	if !reflect.DeepEqual(report.Processors, expected) {
+		t.Errorf("processor report does not match\nexpected: %+v\nreceived: %+v", expected, report.Processors)
+	}
+}
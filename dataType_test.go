@@ -2,10 +2,18 @@ package csvParse
 
 import (
 	"encoding/json"
+	"math"
 	"reflect"
+	"strconv"
 	"testing"
+	"time"
 )
 
+// returns a pointer to a copy of the value for the optional fields of DataTypeOptions
+func ptr[T any](value T) *T {
+	return &value
+}
+
 func TestFloat64Marshalling(t *testing.T) {
 	t.Parallel()
 
@@ -28,3 +36,606 @@ func TestFloat64Marshalling(t *testing.T) {
 		t.Errorf("expected: `{10.513}`\nreceived: %v", data)
 	}
 }
+
+func TestDataTypeDateTime(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value      string
+		layouts    []string
+		expected   time.Time
+		expectFail bool
+	}{
+		{value: "240910T12:53:09", layouts: []string{"060102T15:04:05"}, expected: time.Date(2024, 9, 10, 12, 53, 9, 0, time.Local)},
+		{value: "23.09.2024 08:04", layouts: []string{"%d.%m.%Y %H:%M"}, expected: time.Date(2024, 9, 23, 8, 4, 0, 0, time.Local)},
+		{value: "2024/09/23 08:04:18", layouts: []string{"%F %T", "%Y/%m/%d %T"}, expected: time.Date(2024, 9, 23, 8, 4, 18, 0, time.Local)},
+		{value: "2024/09/23", layouts: []string{"%F"}, expectFail: true},
+		{value: "2024/09/23", layouts: []string{"%Q"}, expectFail: true},
+		{value: "2024/09/23", expectFail: true},
+	}
+
+	for n, test := range tests {
+		data, err := DataTypeDateTime.ReadWithOptions(test.value, &DataTypeOptions{Layouts: test.layouts})
+		if err != nil {
+			if !test.expectFail {
+				t.Errorf("Test %d: error reading value: %v", n, err)
+			}
+			continue
+		} else if test.expectFail {
+			t.Errorf("Test %d: expected error but received %v", n, data)
+			continue
+		}
+		if data != test.expected.Format(time.RFC3339) {
+			t.Errorf("Test %d: expected %s but received %v", n, test.expected.Format(time.RFC3339), data)
+		}
+	}
+
+	config := Csv{
+		PreProcessor: []Processor{},
+		Options:      &DataTypeOptions{Layouts: []string{"%d.%m.%Y %H:%M"}},
+		CellLocations: []CellLocation{
+			{Name: "start", Location: Cell{Row: 0, Column: 1}, DataType: DataTypeDateTime},
+			{Name: "stamp", Location: Cell{Row: 1, Column: 1}, DataType: DataTypeDateTime, Options: &DataTypeOptions{Layouts: []string{"060102T15:04:05"}}},
+		},
+	}
+	configJson, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("error marshalling config: %v", err)
+	}
+	var configFromJson Csv
+	if err = json.Unmarshal(configJson, &configFromJson); err != nil {
+		t.Fatalf("error unmarshaling config: %v", err)
+	} else if !reflect.DeepEqual(config, configFromJson) {
+		t.Errorf("config not equal to configFromJson\nexpected: %v\nreceived: %v", config, configFromJson)
+	}
+
+	data, err := configFromJson.ParseRecords([][]string{{"start", "23.09.2024 08:04"}, {"stamp", "240910T12:53:09"}})
+	if err != nil {
+		t.Fatalf("error parsing records: %v", err)
+	}
+	output := data.(map[string]any)
+	if output["start"] != time.Date(2024, 9, 23, 8, 4, 0, 0, time.Local).Format(time.RFC3339) {
+		t.Errorf("start does not match: %v", output["start"])
+	}
+	if output["stamp"] != time.Date(2024, 9, 10, 12, 53, 9, 0, time.Local).Format(time.RFC3339) {
+		t.Errorf("stamp does not match: %v", output["stamp"])
+	}
+}
+
+func TestDataTypeDateTimeTableColumn(t *testing.T) {
+	t.Parallel()
+
+	table := TableLocation{
+		Name:                "shots",
+		EndCell:             Cell{Row: -1, Column: -1},
+		TableHasHeader:      true,
+		ColumnTypesByHeader: map[string]DataType{"Start*": DataTypeDateTime},
+		Options:             &DataTypeOptions{Layouts: []string{"%d.%m.%Y %H:%M"}},
+		ColumnOptions: map[string]*DataTypeOptions{
+			"Start_Stamp": {Layouts: []string{"060102T15:04:05"}},
+		},
+	}
+
+	_, data, err := table.Parse([][]string{{"Start Date", "Start Stamp"}, {"23.09.2024 08:04", "240910T12:53:09"}}, false)
+	if err != nil {
+		t.Fatalf("error parsing table: %v", err)
+	}
+	expected := []map[string]any{{
+		"Start_Date":  time.Date(2024, 9, 23, 8, 4, 0, 0, time.Local).Format(time.RFC3339),
+		"Start_Stamp": time.Date(2024, 9, 10, 12, 53, 9, 0, time.Local).Format(time.RFC3339),
+	}}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("table does not match\nexpected: %v\nreceived: %v", expected, data)
+	}
+}
+
+func TestDataTypeEpochAndExcel(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dataType   DataType
+		value      string
+		options    DataTypeOptions
+		expected   string
+		expectFail bool
+	}{
+		{dataType: DataTypeEpoch, value: "1727078658", options: DataTypeOptions{Location: "UTC"}, expected: "2024-09-23T08:04:18Z"},
+		{dataType: DataTypeEpoch, value: "1727078658000", options: DataTypeOptions{Location: "UTC", TimeUnit: "ms"}, expected: "2024-09-23T08:04:18Z"},
+		{dataType: DataTypeEpoch, value: "1727078658000000", options: DataTypeOptions{Location: "UTC", TimeUnit: "us"}, expected: "2024-09-23T08:04:18Z"},
+		{dataType: DataTypeEpoch, value: "1727078658000000000", options: DataTypeOptions{Location: "UTC", TimeUnit: "ns"}, expected: "2024-09-23T08:04:18Z"},
+		{dataType: DataTypeEpoch, value: "1727078658.5", options: DataTypeOptions{Location: "Asia/Tokyo"}, expected: "2024-09-23T17:04:18+09:00"},
+		{dataType: DataTypeEpoch, value: "1e12", options: DataTypeOptions{Location: "UTC"}, expected: "33658-09-27T01:46:40Z"},
+		{dataType: DataTypeEpoch, value: "1000000000000", options: DataTypeOptions{Location: "UTC"}, expected: "33658-09-27T01:46:40Z"},
+		{dataType: DataTypeEpoch, value: "1e15", options: DataTypeOptions{Location: "UTC", TimeUnit: "ms"}, expected: "33658-09-27T01:46:40Z"},
+		{dataType: DataTypeEpoch, value: "-1.5", options: DataTypeOptions{Location: "UTC", TimeFormat: TimeFormatRFC3339Nano}, expected: "1969-12-31T23:59:58.5Z"},
+		{dataType: DataTypeEpoch, value: "1e300", options: DataTypeOptions{Location: "UTC"}, expectFail: true},
+		{dataType: DataTypeEpoch, value: "1.5", options: DataTypeOptions{TimeUnit: "days"}, expectFail: true},
+		{dataType: DataTypeEpoch, value: "1727078658", options: DataTypeOptions{TimeUnit: "days"}, expectFail: true},
+		{dataType: DataTypeEpoch, value: "yesterday", expectFail: true},
+		{dataType: DataTypeExcelSerial, value: "45558.5", options: DataTypeOptions{Location: "UTC"}, expected: "2024-09-23T12:00:00Z"},
+		{dataType: DataTypeExcelSerial, value: "44096.25", options: DataTypeOptions{Location: "Asia/Tokyo", ExcelDateSystem: "1904"}, expected: "2024-09-23T06:00:00+09:00"},
+		{dataType: DataTypeExcelSerial, value: "1", options: DataTypeOptions{Location: "UTC"}, expected: "1900-01-01T00:00:00Z"},
+		{dataType: DataTypeExcelSerial, value: "59", options: DataTypeOptions{Location: "UTC"}, expected: "1900-02-28T00:00:00Z"},
+		{dataType: DataTypeExcelSerial, value: "60", options: DataTypeOptions{Location: "UTC"}, expectFail: true},
+		{dataType: DataTypeExcelSerial, value: "60.5", options: DataTypeOptions{Location: "UTC"}, expectFail: true},
+		{dataType: DataTypeExcelSerial, value: "61", options: DataTypeOptions{Location: "UTC"}, expected: "1900-03-01T00:00:00Z"},
+		{dataType: DataTypeExcelSerial, value: "60", options: DataTypeOptions{Location: "UTC", ExcelDateSystem: "1904"}, expected: "1904-03-01T00:00:00Z"},
+		{dataType: DataTypeExcelSerial, value: "45558", options: DataTypeOptions{ExcelDateSystem: "2000"}, expectFail: true},
+		{dataType: DataTypeTimeOffset, value: "20", options: DataTypeOptions{OffsetField: "start"}, expectFail: true},
+	}
+
+	for n, test := range tests {
+		data, err := test.dataType.ReadWithOptions(test.value, &test.options)
+		if err != nil {
+			if !test.expectFail {
+				t.Errorf("Test %d: error reading value: %v", n, err)
+			}
+			continue
+		} else if test.expectFail {
+			t.Errorf("Test %d: expected error but received %v", n, data)
+			continue
+		}
+		if data != test.expected {
+			t.Errorf("Test %d: expected %s but received %v", n, test.expected, data)
+		}
+	}
+}
+
+func TestDataTypeTimeOffset(t *testing.T) {
+	t.Parallel()
+
+	records := [][]string{
+		{"Date", "2024/09/23 09:39:10"},
+		{"Time", "Spd"},
+		{"0", "0.000"},
+		{"1500", "0.193"},
+	}
+	csv := Csv{
+		Options: &DataTypeOptions{Location: "UTC"},
+		TimeFields: []TimeField{
+			{Name: "start", Cells: []Cell{{Row: 0, Column: 1}}, Layout: "2006/01/02 15:04:05"},
+		},
+		TableLocations: []TableLocation{
+			{
+				Name:                "wave",
+				StartCell:           Cell{Row: 1, Column: 0},
+				EndCell:             Cell{Row: -1, Column: -1},
+				TableHasHeader:      true,
+				ColumnTypesByHeader: map[string]DataType{"Time": DataTypeTimeOffset, "Spd": DataTypeFloat64},
+				ColumnOptions:       map[string]*DataTypeOptions{"Time": {OffsetField: "start", TimeUnit: "ms"}},
+			},
+		},
+	}
+
+	data, err := csv.ParseRecords(records)
+	if err != nil {
+		t.Fatalf("error parsing records: %v", err)
+	}
+	wave := data.(map[string]any)["wave"].([]map[string]any)
+	if wave[0]["Time"] != "2024-09-23T09:39:10Z" || wave[1]["Time"] != "2024-09-23T09:39:11Z" {
+		t.Errorf("offsets were not added to the start time: %v", wave)
+	}
+	if data.(map[string]any)["start"] != "2024-09-23T09:39:10Z" {
+		t.Errorf("start time field does not match: %v", data.(map[string]any)["start"])
+	}
+
+	overflow := &DataTypeOptions{OffsetField: "start", baseTimes: map[string]time.Time{"start": time.Unix(0, 0)}}
+	if data, err := DataTypeTimeOffset.ReadWithOptions("1e12", overflow); err == nil {
+		t.Errorf("expected error for offset outside of the range of a duration but received %v", data)
+	}
+
+	_, _, tables, _, err := csv.ParseRecordsSegmented(records)
+	if err != nil {
+		t.Fatalf("error parsing segmented records: %v", err)
+	}
+	if !reflect.DeepEqual(tables["wave"], wave) {
+		t.Errorf("segmented table does not match\nexpected: %v\nreceived: %v", wave, tables["wave"])
+	}
+}
+
+func TestDataTypeNumberOptions(t *testing.T) {
+	t.Parallel()
+
+	european := DataTypeOptions{DecimalSeparator: ",", ThousandsSeparator: "."}
+	units := DataTypeOptions{StripPattern: `\s*(sec|%)$`}
+	percent := DataTypeOptions{PercentToFraction: ptr(true)}
+	tests := []struct {
+		dataType   DataType
+		value      string
+		options    DataTypeOptions
+		expected   any
+		expectFail bool
+	}{
+		{dataType: DataTypeFloat64, value: "1.234,56", options: european, expected: Float64(1234.56)},
+		{dataType: DataTypeInt64, value: "1.234", options: european, expected: int64(1234)},
+		{dataType: DataTypeAuto, value: "1.234,5", options: european, expected: Float64(1234.5)},
+		{dataType: DataTypeAuto, value: "Hello, World", options: european, expected: "Hello, World"},
+		{dataType: DataTypeFloat64, value: "55.3 sec", options: units, expected: Float64(55.3)},
+		{dataType: DataTypeInt64, value: "93%", options: units, expected: int64(93)},
+		{dataType: DataTypeAuto, value: "55.3 sec", options: units, expected: Float64(55.3)},
+		{dataType: DataTypeFloat64, value: "93%", options: percent, expected: Float64(0.93)},
+		{dataType: DataTypeAuto, value: "93 %", options: percent, expected: Float64(0.93)},
+		{dataType: DataTypeInt64, value: "93%", options: percent, expectFail: true},
+		{dataType: DataTypeFloat64, value: "55.3", options: DataTypeOptions{StripPattern: "("}, expectFail: true},
+	}
+
+	for n, test := range tests {
+		data, err := test.dataType.ReadWithOptions(test.value, &test.options)
+		if err != nil {
+			if !test.expectFail {
+				t.Errorf("Test %d: error reading value: %v", n, err)
+			}
+			continue
+		} else if test.expectFail {
+			t.Errorf("Test %d: expected error but received %v", n, data)
+			continue
+		}
+		if data != test.expected {
+			t.Errorf("Test %d: expected %v of type %T but received %v of type %T", n, test.expected, test.expected, data, data)
+		}
+	}
+}
+
+func TestDataTypeBoolVocabulary(t *testing.T) {
+	t.Parallel()
+
+	onOff := DataTypeOptions{TrueValues: []string{"ON", "合格"}, FalseValues: []string{"OFF", "不合格"}}
+	tests := []struct {
+		dataType   DataType
+		value      string
+		options    DataTypeOptions
+		expected   any
+		expectFail bool
+	}{
+		{dataType: DataTypeBool, value: "OK", expected: true},
+		{dataType: DataTypeBool, value: "ng", expected: false},
+		{dataType: DataTypeBool, value: "maybe", expected: nil},
+		{dataType: DataTypeBool, value: "on", options: onOff, expected: true},
+		{dataType: DataTypeBool, value: "不合格", options: onOff, expected: false},
+		{dataType: DataTypeBool, value: "true", options: onOff, expected: true},
+		{dataType: DataTypeBool, value: "ok", options: onOff, expected: nil},
+		{dataType: DataTypeAuto, value: "ok", expected: true},
+		{dataType: DataTypeAuto, value: " OFF", options: onOff, expected: false},
+		{dataType: DataTypeAuto, value: "ok", options: onOff, expected: "ok"},
+		{dataType: DataTypeBool, value: "", options: DataTypeOptions{StrictBool: ptr(true)}, expected: nil},
+		{dataType: DataTypeBool, value: "maybe", options: DataTypeOptions{StrictBool: ptr(true)}, expectFail: true},
+		{dataType: DataTypeBool, value: " true ", options: DataTypeOptions{StrictBool: ptr(true)}, expected: true},
+		{dataType: DataTypeBool, value: " ON ", options: onOff, expected: true},
+		{dataType: DataTypeBool, value: " 0 ", expected: false},
+	}
+
+	for n, test := range tests {
+		data, err := test.dataType.ReadWithOptions(test.value, &test.options)
+		if err != nil {
+			if !test.expectFail {
+				t.Errorf("Test %d: error reading value: %v", n, err)
+			}
+			continue
+		} else if test.expectFail {
+			t.Errorf("Test %d: expected error but received %v", n, data)
+			continue
+		}
+		if data != test.expected {
+			t.Errorf("Test %d: expected %v of type %T but received %v of type %T", n, test.expected, test.expected, data, data)
+		}
+	}
+}
+
+func TestDataTypeNumberFormat(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dataType   DataType
+		value      string
+		options    DataTypeOptions
+		expected   string
+		expectFail bool
+	}{
+		{dataType: DataTypeFloat64, value: "4.80", expected: `4.8`},
+		{dataType: DataTypeAuto, value: "4", expected: `4.0`},
+		{dataType: DataTypeAuto, value: "4", options: DataTypeOptions{KeepIntegers: ptr(true)}, expected: `4`},
+		{dataType: DataTypeAuto, value: "4.50", options: DataTypeOptions{KeepIntegers: ptr(true)}, expected: `4.5`},
+		{dataType: DataTypeFloat64, value: "4.80", options: DataTypeOptions{NumberFormat: NumberFormatDecimal}, expected: `4.80`},
+		{dataType: DataTypeFloat64, value: "4", options: DataTypeOptions{NumberFormat: NumberFormatDecimal}, expected: `4.0`},
+		{dataType: DataTypeFloat64, value: "+.5", options: DataTypeOptions{NumberFormat: NumberFormatDecimal}, expected: `0.5`},
+		{dataType: DataTypeFloat64, value: "1,250.00", options: DataTypeOptions{NumberFormat: NumberFormatDecimal, ThousandsSeparator: ","}, expected: `1250.00`},
+		{dataType: DataTypeAuto, value: "4", options: DataTypeOptions{NumberFormat: NumberFormatJSONNumber}, expected: `4`},
+		{dataType: DataTypeAuto, value: "1.10e3", options: DataTypeOptions{NumberFormat: NumberFormatJSONNumber}, expected: `1.10e3`},
+		{dataType: DataTypeFloat64, value: "12.5%", options: DataTypeOptions{NumberFormat: NumberFormatJSONNumber, PercentToFraction: ptr(true)}, expected: `0.125`},
+		{dataType: DataTypeFloat64, value: "NaN", expected: `null`},
+		{dataType: DataTypeAuto, value: "-Inf", expected: `null`},
+		{dataType: DataTypeFloat64, value: "NaN", options: DataTypeOptions{NonFinite: NonFiniteString}, expected: `"NaN"`},
+		{dataType: DataTypeFloat64, value: "Inf", options: DataTypeOptions{NonFinite: NonFiniteError}, expectFail: true},
+		{dataType: DataTypeFloat64, value: "4.8", options: DataTypeOptions{NumberFormat: "exact"}, expectFail: true},
+	}
+
+	for n, test := range tests {
+		data, err := test.dataType.ReadWithOptions(test.value, &test.options)
+		if err != nil {
+			if !test.expectFail {
+				t.Errorf("Test %d: error reading value: %v", n, err)
+			}
+			continue
+		} else if test.expectFail {
+			t.Errorf("Test %d: expected error but received %v", n, data)
+			continue
+		}
+		result, err := json.Marshal(data)
+		if err != nil {
+			t.Errorf("Test %d: error marshaling %v: %v", n, data, err)
+			continue
+		}
+		if string(result) != test.expected {
+			t.Errorf("Test %d: expected %s but received %s", n, test.expected, result)
+		}
+	}
+
+	if _, err := json.Marshal(Float64(math.NaN())); err == nil {
+		t.Errorf("expected error marshaling NaN")
+	}
+}
+
+func TestDataTypeDuration(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		value      string
+		options    DataTypeOptions
+		expected   any
+		expectFail bool
+	}{
+		{value: "55.3", expected: Float64(55.3)},
+		{value: "00:00:55.3", expected: Float64(55.3)},
+		{value: "01:02", expected: Float64(62)},
+		{value: "-1:00:00", expected: Float64(-3600)},
+		{value: "1m2s", expected: Float64(62)},
+		{value: "PT1M2.5S", expected: Float64(62.5)},
+		{value: "55300", options: DataTypeOptions{TimeUnit: "ms"}, expected: Float64(55.3)},
+		{value: "55.3", options: DataTypeOptions{DurationFormat: DurationFormatMillis}, expected: int64(55300)},
+		{value: "1:02:03.5", options: DataTypeOptions{DurationFormat: DurationFormatISO8601}, expected: "PT1H2M3.5S"},
+		{value: "120", options: DataTypeOptions{DurationFormat: DurationFormatISO8601}, expected: "PT2M"},
+		{value: "0", options: DataTypeOptions{DurationFormat: DurationFormatISO8601}, expected: "PT0S"},
+		{value: "", expected: nil},
+		{value: "1:2:3:4", expectFail: true},
+		{value: "soon", expectFail: true},
+		{value: "1", options: DataTypeOptions{DurationFormat: "minutes"}, expectFail: true},
+		{value: "1e12", expectFail: true},
+		{value: "-1e12", expectFail: true},
+		{value: "9e15", options: DataTypeOptions{TimeUnit: "ms"}, expectFail: true},
+		{value: "4000000000:00:00", expectFail: true},
+		{value: "PT2562047H47M16.854775807S", expected: Float64(9223372036.854775807)},
+		{value: "PT2562047H47M17S", expectFail: true},
+		{value: "P999999999D", expectFail: true},
+	}
+
+	for n, test := range tests {
+		data, err := DataTypeDuration.ReadWithOptions(test.value, &test.options)
+		if err != nil {
+			if !test.expectFail {
+				t.Errorf("Test %d: error reading value: %v", n, err)
+			}
+			continue
+		} else if test.expectFail {
+			t.Errorf("Test %d: expected error but received %v", n, data)
+			continue
+		}
+		if data != test.expected {
+			t.Errorf("Test %d: expected %v of type %T but received %v of type %T", n, test.expected, test.expected, data, data)
+		}
+	}
+}
+
+func TestDataTypeIntLiteral(t *testing.T) {
+	t.Parallel()
+
+	status := map[int]string{0: "running", 1: "fault", 3: "estop", 15: "alarm"}
+	tests := []struct {
+		dataType   DataType
+		value      string
+		options    DataTypeOptions
+		expected   any
+		expectFail bool
+	}{
+		{dataType: DataTypeIntLiteral, value: "0x1A3F", expected: int64(0x1A3F)},
+		{dataType: DataTypeIntLiteral, value: "0o17", expected: int64(15)},
+		{dataType: DataTypeIntLiteral, value: "0B1010", expected: int64(10)},
+		{dataType: DataTypeIntLiteral, value: "0010", expected: int64(10)},
+		{dataType: DataTypeIntLiteral, value: "-0x10", expected: int64(-16)},
+		{dataType: DataTypeIntLiteral, value: "0xFFFFFFFFFFFFFFFF", expected: int64(-1)},
+		{dataType: DataTypeIntLiteral, value: "9223372036854775807", expected: int64(math.MaxInt64)},
+		{dataType: DataTypeIntLiteral, value: "-9223372036854775808", expected: int64(math.MinInt64)},
+		{dataType: DataTypeIntLiteral, value: "9223372036854775808", expectFail: true},
+		{dataType: DataTypeIntLiteral, value: "18446744073709551615", expectFail: true},
+		{dataType: DataTypeIntLiteral, value: "-0x8000000000000001", expectFail: true},
+		{dataType: DataTypeIntLiteral, value: "", expected: nil},
+		{dataType: DataTypeIntLiteral, value: "0xZZ", expectFail: true},
+		{dataType: DataTypeHex, value: "1a3f", expected: int64(0x1A3F)},
+		{dataType: DataTypeHex, value: "0x1A3F", expected: int64(0x1A3F)},
+		{dataType: DataTypeHex, value: "0b12", expected: int64(0xB12)},
+		{dataType: DataTypeIntLiteral, value: "0x800B", options: DataTypeOptions{Bitfield: status},
+			expected: map[string]any{"running": true, "fault": true, "estop": true, "alarm": true}},
+		{dataType: DataTypeInt64, value: "2", options: DataTypeOptions{Bitfield: status},
+			expected: map[string]any{"running": false, "fault": true, "estop": false, "alarm": false}},
+		{dataType: DataTypeHex, value: "8009", options: DataTypeOptions{Bitfield: status, BitfieldFlags: ptr(true)},
+			expected: []string{"running", "estop", "alarm"}},
+		{dataType: DataTypeHex, value: "0", options: DataTypeOptions{Bitfield: status, BitfieldFlags: ptr(true)},
+			expected: []string{}},
+		{dataType: DataTypeHex, value: "1", options: DataTypeOptions{Bitfield: map[int]string{64: "overflow"}}, expectFail: true},
+	}
+
+	for n, test := range tests {
+		data, err := test.dataType.ReadWithOptions(test.value, &test.options)
+		if err != nil {
+			if !test.expectFail {
+				t.Errorf("Test %d: error reading value: %v", n, err)
+			}
+			continue
+		} else if test.expectFail {
+			t.Errorf("Test %d: expected error but received %v", n, data)
+			continue
+		}
+		if !reflect.DeepEqual(data, test.expected) {
+			t.Errorf("Test %d: expected %v of type %T but received %v of type %T", n, test.expected, test.expected, data, data)
+		}
+	}
+}
+
+func TestDataTypeListAndJSON(t *testing.T) {
+	t.Parallel()
+
+	tests := []struct {
+		dataType   DataType
+		value      string
+		options    DataTypeOptions
+		expected   any
+		expectFail bool
+	}{
+		{dataType: DataTypeList, value: "A12;A13;A19", options: DataTypeOptions{ListElement: ptr(DataTypeString)}, expected: []any{"A12", "A13", "A19"}},
+		{dataType: DataTypeList, value: "1; 2;;3;", options: DataTypeOptions{ListElement: ptr(DataTypeInt64)}, expected: []any{int64(1), int64(2), int64(3)}},
+		{dataType: DataTypeList, value: "1.5|ok", options: DataTypeOptions{ListSeparator: "|"}, expected: []any{Float64(1.5), true}},
+		{dataType: DataTypeList, value: "", expected: nil},
+		{dataType: DataTypeList, value: "1;x", options: DataTypeOptions{ListElement: ptr(DataTypeInt64)}, expectFail: true},
+		{dataType: DataTypeList, value: "1", options: DataTypeOptions{ListElement: ptr(DataTypeList)}, expectFail: true},
+		{dataType: DataTypeJSON, value: `{"lot": "L1", "qty": 12}`, expected: map[string]any{"lot": "L1", "qty": json.Number("12")}},
+		{dataType: DataTypeJSON, value: ` [1, "a", null] `, expected: []any{json.Number("1"), "a", nil}},
+		{dataType: DataTypeJSON, value: "", expected: nil},
+		{dataType: DataTypeJSON, value: `"text"`, expectFail: true},
+		{dataType: DataTypeJSON, value: `{"a": 1} {"b": 2}`, expectFail: true},
+		{dataType: DataTypeJSON, value: `{"a": }`, expectFail: true},
+	}
+
+	for n, test := range tests {
+		data, err := test.dataType.ReadWithOptions(test.value, &test.options)
+		if err != nil {
+			if !test.expectFail {
+				t.Errorf("Test %d: error reading value: %v", n, err)
+			}
+			continue
+		} else if test.expectFail {
+			t.Errorf("Test %d: expected error but received %v", n, data)
+			continue
+		}
+		if !reflect.DeepEqual(data, test.expected) {
+			t.Errorf("Test %d: expected %v of type %T but received %v of type %T", n, test.expected, test.expected, data, data)
+		}
+	}
+
+	records := [][]string{
+		{"Lot", `{"recipe": "R7"`, `, "step": 3}`},
+		{"Shot", "Cavities", "Data"},
+		{"1", "A12;A13", `[1, 2]`},
+		{"2", "A19", `[]`},
+	}
+	c := Csv{
+		ConcatCellLocations: []ConcatCellLocation{
+			{Cells: []Cell{{Row: 0, Column: 1}, {Row: 0, Column: 2}}, Name: "Lot", DataType: DataTypeJSON},
+		},
+		TableLocations: []TableLocation{{
+			Name:                "shots",
+			StartCell:           Cell{Row: 1, Column: 0},
+			EndCell:             Cell{Row: -1, Column: -1},
+			TableHasHeader:      true,
+			ColumnTypesByHeader: map[string]DataType{"Shot": DataTypeInt64, "Cavities": DataTypeList, "Data": DataTypeJSON},
+			ColumnOptions:       map[string]*DataTypeOptions{"Cavities": {ListElement: ptr(DataTypeString)}},
+		}},
+	}
+	data, err := c.ParseRecords(records)
+	if err != nil {
+		t.Fatalf("failed to parse records: %v", err)
+	}
+	expected := map[string]any{
+		"Lot": map[string]any{"recipe": "R7", "step": json.Number("3")},
+		"shots": []map[string]any{
+			{"Shot": int64(1), "Cavities": []any{"A12", "A13"}, "Data": []any{json.Number("1"), json.Number("2")}},
+			{"Shot": int64(2), "Cavities": []any{"A19"}, "Data": []any{}},
+		},
+	}
+	if !reflect.DeepEqual(data, expected) {
+		t.Errorf("records do not match\nexpected: %v\nreceived: %v", expected, data)
+	}
+}
+
+func TestDataTypeTransform(t *testing.T) {
+	t.Parallel()
+
+	zero, one, two, three := 0, 1, 2, 3
+	tests := []struct {
+		dataType   DataType
+		value      string
+		options    DataTypeOptions
+		expected   any
+		expectFail bool
+	}{
+		{dataType: DataTypeFloat64, value: "2048", options: DataTypeOptions{Scale: ptr(0.1), Offset: ptr(-20.0)}, expected: Float64(184.8)},
+		{dataType: DataTypeInt64, value: "2048", options: DataTypeOptions{Scale: ptr(0.1)}, expected: Float64(204.8)},
+		{dataType: DataTypeInt64, value: "1234", options: DataTypeOptions{Scale: ptr(0.01), NumberFormat: NumberFormatDecimal}, expected: Decimal("12.34")},
+		{dataType: DataTypeInt64, value: "100", options: DataTypeOptions{FromUnit: "psi", ToUnit: "MPa", RoundDigits: &three}, expected: Float64(0.689)},
+		{dataType: DataTypeInt64, value: "2048", options: DataTypeOptions{Scale: ptr(0.1), RoundDigits: &zero}, expected: int64(205)},
+		{dataType: DataTypeInt64, value: "2048", options: DataTypeOptions{Scale: ptr(2.0), Offset: ptr(-96.0)}, expected: int64(4000)},
+		{dataType: DataTypeFloat64, value: "212", options: DataTypeOptions{FromUnit: "°F", ToUnit: "°C"}, expected: Float64(100)},
+		{dataType: DataTypeFloat64, value: "0", options: DataTypeOptions{FromUnit: "°C", ToUnit: "K"}, expected: Float64(273.15)},
+		{dataType: DataTypeFloat64, value: "1450", options: DataTypeOptions{FromUnit: "psi", ToUnit: "MPa", RoundDigits: &two}, expected: Float64(10)},
+		{dataType: DataTypeAuto, value: "1", options: DataTypeOptions{FromUnit: "(in)", ToUnit: "mm"}, expected: Float64(25.4)},
+		{dataType: DataTypeFloat64, value: "2.2", options: DataTypeOptions{FromUnit: "lb", ToUnit: "kg", RoundDigits: &one}, expected: Float64(1)},
+		{dataType: DataTypeDuration, value: "1:30", options: DataTypeOptions{ToUnit: "min", FromUnit: "s"}, expected: Float64(1.5)},
+		{dataType: DataTypeFloat64, value: "3.14159", options: DataTypeOptions{RoundDigits: &zero}, expected: Float64(3)},
+		{dataType: DataTypeFloat64, value: "10.5", options: DataTypeOptions{RoundDigits: &two, NumberFormat: NumberFormatDecimal}, expected: Decimal("10.50")},
+		{dataType: DataTypeString, value: "10", options: DataTypeOptions{Scale: ptr(2.0)}, expected: "10"},
+		{dataType: DataTypeAuto, value: "", options: DataTypeOptions{Scale: ptr(2.0)}, expected: nil},
+		{dataType: DataTypeFloat64, value: "1", options: DataTypeOptions{ToUnit: "°C"}, expectFail: true},
+		{dataType: DataTypeFloat64, value: "1", options: DataTypeOptions{FromUnit: "psi", ToUnit: "°C"}, expectFail: true},
+		{dataType: DataTypeFloat64, value: "1", options: DataTypeOptions{FromUnit: "furlong", ToUnit: "m"}, expectFail: true},
+	}
+
+	for n, test := range tests {
+		data, err := test.dataType.ReadWithOptions(test.value, &test.options)
+		if err != nil {
+			if !test.expectFail {
+				t.Errorf("Test %d: error reading value: %v", n, err)
+			}
+			continue
+		} else if test.expectFail {
+			t.Errorf("Test %d: expected error but received %v", n, data)
+			continue
+		}
+		if data != test.expected {
+			t.Errorf("Test %d: expected %v of type %T but received %v of type %T", n, test.expected, test.expected, data, data)
+		}
+	}
+}
+
+func TestDataTypeNames(t *testing.T) {
+	t.Parallel()
+
+	for dataType, name := range dataTypeNames {
+		data, err := json.Marshal(dataType)
+		if err != nil {
+			t.Errorf("error marshaling data type %d: %v", dataType, err)
+			continue
+		} else if string(data) != `"`+name+`"` {
+			t.Errorf("data type %d marshaled as %s instead of %q", dataType, data, name)
+		}
+
+		var fromName, fromInteger DataType
+		if err = json.Unmarshal(data, &fromName); err != nil || fromName != dataType {
+			t.Errorf("data type %s unmarshaled as %d: %v", name, fromName, err)
+		}
+		if err = json.Unmarshal([]byte(strconv.Itoa(int(dataType))), &fromInteger); err != nil || fromInteger != dataType {
+			t.Errorf("legacy data type %d unmarshaled as %d: %v", dataType, fromInteger, err)
+		}
+	}
+
+	if DataTypeDateTimeStyle0.String() != "dateTimeStyle0" || DataTypeFloat64.String() != "float64" {
+		t.Errorf("unexpected names %s and %s", DataTypeDateTimeStyle0, DataTypeFloat64)
+	}
+
+	var dataType DataType
+	for _, invalid := range []string{`"float"`, `99`, `-1`, `true`} {
+		if err := json.Unmarshal([]byte(invalid), &dataType); err == nil {
+			t.Errorf("expected error unmarshaling %s", invalid)
+		}
+	}
+	if _, err := json.Marshal(DataType(99)); err == nil {
+		t.Errorf("expected error marshaling unknown data type")
+	}
+}
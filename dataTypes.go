@@ -1,7 +1,13 @@
 package csvParse
 
 import (
+	"encoding/json"
 	"fmt"
+	"io"
+	"maps"
+	"math"
+	"regexp"
+	"slices"
 	"strconv"
 	"strings"
 	"time"
@@ -10,6 +16,9 @@ import (
 type Float64 float64 // Custom type to handle mapping with decimals
 
 func (f Float64) MarshalJSON() ([]byte, error) {
+	if math.IsNaN(float64(f)) || math.IsInf(float64(f), 0) {
+		return nil, fmt.Errorf("unable to marshal %v: NaN and Inf are not valid JSON", float64(f))
+	}
 	str := strconv.FormatFloat(float64(f), 'f', -1, 64)
 	if !strings.Contains(str, ".") {
 		str += ".0"
@@ -17,6 +26,21 @@ func (f Float64) MarshalJSON() ([]byte, error) {
 	return []byte(str), nil
 }
 
+// Decimal number which keeps the digits of the source text when marshaled
+type Decimal string
+
+func (d Decimal) MarshalJSON() ([]byte, error) {
+	str := string(d)
+	if !strings.ContainsAny(str, ".eE") {
+		str += ".0"
+	}
+	return []byte(str), nil
+}
+
+func (d Decimal) Float64() (float64, error) {
+	return strconv.ParseFloat(string(d), 64)
+}
+
 // Type mapping
 type DataType int
 
@@ -29,87 +53,781 @@ const (
 	DataTypeBool
 	DataTypeDateTimeStyle0 // Assumes local time | YYYY-MM-DD HH:MM:SS
 	DataTypeDateTimeStyle1 // Assumes local time | YYYY/MM/DD HH:MM:SS
+	DataTypeDateTime       // Assumes local time | Uses the Layouts of the DataTypeOptions
+	DataTypeEpoch          // Time since 1970-01-01 UTC in the TimeUnit of the DataTypeOptions
+	DataTypeExcelSerial    // Assumes local time | Days since the start of the ExcelDateSystem of the DataTypeOptions
+	DataTypeTimeOffset     // Time in the TimeUnit of the DataTypeOptions added to the OffsetField of the DataTypeOptions
+	DataTypeDuration       // Number in the TimeUnit of the DataTypeOptions, HH:MM:SS, Go duration (1m2s) or ISO 8601 duration (PT1M2S)
+	DataTypeIntLiteral     // Integer which can have a 0x, 0o or 0b prefix. Decimal if there is no prefix. Prefixed 64 bit words can wrap to negative
+	DataTypeHex            // Hexadecimal integer with an optional 0x prefix. 64 bit words can wrap to negative
+	DataTypeList           // List split by the ListSeparator of the DataTypeOptions with elements read as the ListElement
+	DataTypeJSON           // JSON object or array
 )
 
+// Stable names used when a DataType is marshaled. Names must never change
+var dataTypeNames = map[DataType]string{
+	DataTypeAuto:           "auto",
+	DataTypeSplit:          "split",
+	DataTypeString:         "string",
+	DataTypeInt64:          "int64",
+	DataTypeFloat64:        "float64",
+	DataTypeBool:           "bool",
+	DataTypeDateTimeStyle0: "dateTimeStyle0",
+	DataTypeDateTimeStyle1: "dateTimeStyle1",
+	DataTypeDateTime:       "datetime",
+	DataTypeEpoch:          "epoch",
+	DataTypeExcelSerial:    "excelSerial",
+	DataTypeTimeOffset:     "timeOffset",
+	DataTypeDuration:       "duration",
+	DataTypeIntLiteral:     "intLiteral",
+	DataTypeHex:            "hex",
+	DataTypeList:           "list",
+	DataTypeJSON:           "json",
+}
+
 func (dt DataType) String() string {
+	if name, exists := dataTypeNames[dt]; exists {
+		return name
+	}
+	return "unknown"
+}
+
+// returns the Go layout of the fixed style datetime data types
+func (dt DataType) layout() string {
 	switch dt {
-	case DataTypeSplit:
-		return "split"
-	case DataTypeString:
-		return "string"
-	case DataTypeInt64:
-		return "int64"
-	case DataTypeFloat64:
-		return "Float64"
-	case DataTypeBool:
-		return "bool"
-	case DataTypeAuto:
-		return "auto"
 	case DataTypeDateTimeStyle0:
 		return "2006-01-02 15:04:05"
 	case DataTypeDateTimeStyle1:
 		return "2006/01/02 15:04:05"
 	default:
-		return "unknown"
+		return ""
+	}
+}
+
+// Marshals the data type as its name
+func (dt DataType) MarshalJSON() ([]byte, error) {
+	name, exists := dataTypeNames[dt]
+	if !exists {
+		return nil, fmt.Errorf("unknown data type: %d", dt)
 	}
+	return json.Marshal(name)
+}
+
+// Unmarshals the data type from its name or from the integer used by older configs
+func (dt *DataType) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var value DataType
+	if len(data) > 0 && data[0] == '"' {
+		var name string
+		if err := json.Unmarshal(data, &name); err != nil {
+			return fmt.Errorf("error unmarshaling data type: %w", err)
+		}
+		found := false
+		for dataType, dataTypeName := range dataTypeNames {
+			if dataTypeName == name {
+				value, found = dataType, true
+				break
+			}
+		}
+		if !found {
+			return fmt.Errorf("unknown data type: %s", name)
+		}
+	} else {
+		var number int
+		if err := json.Unmarshal(data, &number); err != nil {
+			return fmt.Errorf("error unmarshaling data type: %w", err)
+		}
+		value = DataType(number)
+		if _, exists := dataTypeNames[value]; !exists {
+			return fmt.Errorf("unknown data type: %d", number)
+		}
+	}
+	*dt = value
+	return nil
 }
 
 func (dt DataType) Read(value string) (any, error) {
+	return dt.ReadWithOptions(value, nil)
+}
+
+// Reads the value using the options for data types that are parameterized
+func (dt DataType) ReadWithOptions(value string, options *DataTypeOptions) (any, error) {
+	if options == nil {
+		options = &DataTypeOptions{}
+	}
+	if options.isNull(value) {
+		return nil, nil
+	}
+	if options.Mapping != nil {
+		label, mapped, err := options.Mapping.lookup(value)
+		if err != nil || mapped {
+			return label, err
+		}
+	}
+
+	data, err := dt.read(value, options)
+	if err != nil || !options.hasTransform() {
+		return data, err
+	}
+	return transformNumber(data, options)
+}
+
+func (dt DataType) read(value string, options *DataTypeOptions) (any, error) {
 	switch dt {
 	case DataTypeAuto:
-		return dt.readAuto(value), nil
+		return dt.readAuto(value, options), nil
 	case DataTypeSplit:
-		return dt.readAuto(value), nil
+		return dt.readAuto(value, options), nil
 	case DataTypeString:
 		return value, nil
 	case DataTypeInt64:
-		return strconv.ParseInt(value, 10, 64)
+		number, percent, err := normalizeNumber(value, options)
+		if err != nil {
+			return nil, err
+		} else if percent {
+			return nil, fmt.Errorf("percentage (%s) cannot be read as %s", value, dt)
+		}
+		data, err := strconv.ParseInt(number, 10, 64)
+		if err != nil {
+			return nil, err
+		}
+		return readBitfield(data, options)
 	case DataTypeFloat64:
-		val, err := strconv.ParseFloat(value, 64)
-		return Float64(val), err
+		number, percent, err := normalizeNumber(value, options)
+		if err != nil {
+			return nil, err
+		}
+		val, err := strconv.ParseFloat(number, 64)
+		if err != nil {
+			return nil, err
+		}
+		return formatNumber(value, number, val, percent, options)
 	case DataTypeBool:
-		data, err := strconv.ParseBool(value)
-		if err == nil {
+		data, ok := readBool(value, options)
+		if ok {
 			return data, nil
+		} else if isSet(options.StrictBool) && strings.TrimSpace(value) != "" {
+			return nil, fmt.Errorf("value (%s) is not a recognized bool", value)
 		}
-		switch strings.ToLower(value) {
-		case "ok":
-			return true, nil
-		case "ng":
-			return false, nil
-		default:
-			return nil, nil
-		}
+		return nil, nil
 	case DataTypeDateTimeStyle0:
-		return dt.readDate(value)
+		return dt.readDate(value, options)
 	case DataTypeDateTimeStyle1:
-		return dt.readDate(value)
+		return dt.readDate(value, options)
+	case DataTypeDateTime:
+		return dt.readDateLayouts(value, options)
+	case DataTypeEpoch:
+		return dt.readEpoch(value, options)
+	case DataTypeExcelSerial:
+		return dt.readExcelSerial(value, options)
+	case DataTypeTimeOffset:
+		return dt.readTimeOffset(value, options)
+	case DataTypeDuration:
+		return dt.readDuration(value, options)
+	case DataTypeIntLiteral, DataTypeHex:
+		return dt.readIntLiteral(value, options)
+	case DataTypeList:
+		return dt.readList(value, options)
+	case DataTypeJSON:
+		return dt.readJSON(value)
 	default:
 		return nil, fmt.Errorf("unknown data type: %d", dt)
 	}
 }
 
-func (dt *DataType) readDate(value string) (result any, err error) {
-	data, err := time.ParseInLocation(dt.String(), value, time.Local)
+func (dt *DataType) readDate(value string, options *DataTypeOptions) (result any, err error) {
+	data, err := parseTime(dt.layout(), value, options)
 	if err != nil {
 		return nil, fmt.Errorf("failed to parse date: %w", err)
 	}
-	return data.Format(time.RFC3339), nil
+	return formatTime(data, options)
 }
 
-func (dt *DataType) readNumber(value string, defaultToFloat bool) (result any, err error) {
-	if !defaultToFloat {
+// tries each layout in order and returns the first successful parse
+func (dt *DataType) readDateLayouts(value string, options *DataTypeOptions) (result any, err error) {
+	if len(options.Layouts) == 0 {
+		return nil, fmt.Errorf("no layouts provided for data type %s", dt)
+	}
+
+	for _, layout := range options.Layouts {
+		layout, err = goLayout(layout)
+		if err != nil {
+			return nil, err
+		}
+		data, parseErr := parseTime(layout, value, options)
+		if parseErr == nil {
+			return formatTime(data, options)
+		}
+		err = parseErr
+	}
+	return nil, fmt.Errorf("failed to parse date with any of the layouts %v: %w", options.Layouts, err)
+}
+
+func (dt *DataType) readEpoch(value string, options *DataTypeOptions) (result any, err error) {
+	location, err := loadLocation(options.Location)
+	if err != nil {
+		return nil, err
+	}
+
+	// integers are converted directly to avoid losing precision
+	if integer, err := strconv.ParseInt(value, 10, 64); err == nil {
+		data, err := unixTime(integer, options.TimeUnit)
+		if err != nil {
+			return nil, err
+		}
+		return formatTime(data.In(location), options)
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse epoch: %w", err)
+	}
+
+	// the whole units are converted the same as integers and the fraction is added
+	// so the same instant is read the same no matter how it is written
+	whole, fraction := math.Modf(number)
+	if math.IsNaN(whole) || whole >= math.MaxInt64 || whole < math.MinInt64 {
+		return nil, fmt.Errorf("epoch (%s) is out of range", value)
+	}
+	data, err := unixTime(int64(whole), options.TimeUnit)
+	if err != nil {
+		return nil, err
+	}
+	offset, err := unitDuration(fraction, options.TimeUnit)
+	if err != nil {
+		return nil, err
+	}
+	return formatTime(data.Add(offset).In(location), options)
+}
+
+// converts a whole number of time units since 1970-01-01 UTC to a time
+func unixTime(integer int64, unit string) (time.Time, error) {
+	switch unit {
+	case "", "s":
+		return time.Unix(integer, 0), nil
+	case "ms":
+		return time.UnixMilli(integer), nil
+	case "us":
+		return time.UnixMicro(integer), nil
+	case "ns":
+		return time.Unix(0, integer), nil
+	default:
+		return time.Time{}, fmt.Errorf("invalid time unit: %s", unit)
+	}
+}
+
+func (dt *DataType) readExcelSerial(value string, options *DataTypeOptions) (result any, err error) {
+	serial, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse excel serial: %w", err)
+	}
+
+	var base time.Time
+	switch options.ExcelDateSystem {
+	case "", "1900":
+		// Excel treats 1900 as a leap year so serials after February 28 1900 are offset by a day
+		// and serial 60 is February 29 1900 which does not exist
+		if serial >= 60 && serial < 61 {
+			return nil, fmt.Errorf("excel serial (%s) is February 29 1900 which does not exist", value)
+		}
+		base = time.Date(1899, 12, 30, 0, 0, 0, 0, time.UTC)
+		if serial < 60 {
+			base = base.AddDate(0, 0, 1)
+		}
+	case "1904":
+		base = time.Date(1904, 1, 1, 0, 0, 0, 0, time.UTC)
+	default:
+		return nil, fmt.Errorf("invalid excel date system: %s", options.ExcelDateSystem)
+	}
+
+	days := math.Floor(serial)
+	// fractions of a day are rounded to the millisecond to remove floating point noise
+	wall := base.AddDate(0, 0, int(days)).Add(time.Duration(math.Round((serial-days)*24*60*60*1000)) * time.Millisecond)
+
+	location, err := loadLocation(options.Location)
+	if err != nil {
+		return nil, err
+	}
+	data, err := resolveWallTime(wall, location, options.AmbiguousTime)
+	if err != nil {
+		return nil, err
+	}
+	return formatTime(data, options)
+}
+
+func (dt *DataType) readTimeOffset(value string, options *DataTypeOptions) (result any, err error) {
+	base, exists := options.baseTimes[options.OffsetField]
+	if !exists {
+		return nil, fmt.Errorf("offset field (%s) not found in time fields", options.OffsetField)
+	}
+
+	number, err := strconv.ParseFloat(value, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse time offset: %w", err)
+	}
+	duration, err := unitDuration(number, options.TimeUnit)
+	if err != nil {
+		return nil, err
+	}
+	return formatTime(base.Add(duration), options)
+}
+
+// splits the value and reads each non blank element with the ListElement of the options
+func (dt *DataType) readList(value string, options *DataTypeOptions) (result any, err error) {
+	if strings.TrimSpace(value) == "" {
+		return nil, nil
+	}
+	element := DataTypeAuto
+	if options.ListElement != nil {
+		element = *options.ListElement
+	}
+	if element == DataTypeList {
+		return nil, fmt.Errorf("list elements cannot be of data type %s", element)
+	}
+
+	separator := options.ListSeparator
+	if separator == "" {
+		separator = ";"
+	}
+
+	list := []any{}
+	for _, part := range strings.Split(value, separator) {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		data, err := element.ReadWithOptions(part, options)
+		if err != nil {
+			return nil, fmt.Errorf("error reading list element (%s): %w", part, err)
+		}
+		list = append(list, data)
+	}
+	return list, nil
+}
+
+// decodes a JSON object or array. Numbers are kept as json.Number so no precision is lost
+func (dt *DataType) readJSON(value string) (result any, err error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+	if value[0] != '{' && value[0] != '[' {
+		return nil, fmt.Errorf("value (%s) is not a JSON object or array", value)
+	}
+
+	decoder := json.NewDecoder(strings.NewReader(value))
+	decoder.UseNumber()
+	if err := decoder.Decode(&result); err != nil {
+		return nil, fmt.Errorf("failed to decode JSON: %w", err)
+	}
+	if _, err := decoder.Token(); err != io.EOF {
+		return nil, fmt.Errorf("value (%s) contains data after the JSON %T", value, result)
+	}
+	return result, nil
+}
+
+func (dt *DataType) readIntLiteral(value string, options *DataTypeOptions) (result any, err error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	number, negative := strings.CutPrefix(value, "-")
+	base := 10
+	prefixes := map[string]int{"0x": 16, "0o": 8, "0b": 2}
+	if *dt == DataTypeHex {
+		// b is a hex digit so only 0x is a prefix
+		base = 16
+		prefixes = map[string]int{"0x": 16}
+	}
+	if len(number) > 2 {
+		if prefixBase, exists := prefixes[strings.ToLower(number[:2])]; exists {
+			base = prefixBase
+			number = number[2:]
+		}
+	}
+
+	// parsed as unsigned so hex, octal and binary 64 bit words with the high bit set
+	// are kept as their two's complement value. Decimals must fit in an int64
+	word, err := strconv.ParseUint(strings.ReplaceAll(number, "_", ""), base, 64)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse %s: %w", dt, err)
+	}
+	data := int64(word)
+	switch {
+	case negative && word > 1<<63:
+		return nil, fmt.Errorf("value (%s) overflows int64", value)
+	case negative:
+		data = -data
+	case base == 10 && word > math.MaxInt64:
+		return nil, fmt.Errorf("value (%s) overflows int64", value)
+	}
+	return readBitfield(data, options)
+}
+
+// expands an integer into the flags of the Bitfield of the options.
+// The integer is returned as is if there is no Bitfield
+func readBitfield(data int64, options *DataTypeOptions) (any, error) {
+	if len(options.Bitfield) == 0 {
+		return data, nil
+	}
+
+	bits := slices.Sorted(maps.Keys(options.Bitfield))
+	if bits[0] < 0 || bits[len(bits)-1] > 63 {
+		return nil, fmt.Errorf("bitfield bits must be between 0 and 63")
+	}
+
+	if isSet(options.BitfieldFlags) {
+		flags := []string{}
+		for _, bit := range bits {
+			if uint64(data)&(1<<bit) != 0 {
+				flags = append(flags, options.Bitfield[bit])
+			}
+		}
+		return flags, nil
+	}
+
+	fields := make(map[string]any, len(bits))
+	for _, bit := range bits {
+		fields[options.Bitfield[bit]] = uint64(data)&(1<<bit) != 0
+	}
+	return fields, nil
+}
+
+var isoDurationPattern = regexp.MustCompile(`^(-)?P(?:(\d+)D)?(?:T(?:(\d+)H)?(?:(\d+)M)?(?:(\d+(?:\.\d+)?)S)?)?$`)
+
+func (dt *DataType) readDuration(value string, options *DataTypeOptions) (result any, err error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return nil, nil
+	}
+
+	duration, err := parseDuration(value, options)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse duration: %w", err)
+	}
+
+	switch options.DurationFormat {
+	case DurationFormatSeconds:
+		seconds := duration.Seconds()
+		return formatNumber(value, strconv.FormatFloat(seconds, 'f', -1, 64), seconds, false, options)
+	case DurationFormatMillis:
+		return duration.Milliseconds(), nil
+	case DurationFormatISO8601:
+		return isoDuration(duration), nil
+	default:
+		return nil, fmt.Errorf("invalid duration format: %s", options.DurationFormat)
+	}
+}
+
+// parses a number in the TimeUnit of the options, a clock duration (HH:MM:SS or MM:SS),
+// a Go duration or an ISO 8601 duration
+func parseDuration(value string, options *DataTypeOptions) (time.Duration, error) {
+	if number, err := strconv.ParseFloat(value, 64); err == nil {
+		return unitDuration(number, options.TimeUnit)
+	}
+
+	if strings.Contains(value, ":") {
+		clock, negative := strings.CutPrefix(value, "-")
+		parts := strings.Split(clock, ":")
+		if len(parts) > 3 {
+			return 0, fmt.Errorf("invalid clock duration: %s", value)
+		}
+		seconds, err := strconv.ParseFloat(parts[len(parts)-1], 64)
+		if err != nil {
+			return 0, fmt.Errorf("invalid seconds in clock duration %s: %w", value, err)
+		}
+		duration, err := scaleDuration(seconds, time.Second)
+		if err != nil {
+			return 0, err
+		}
+		for n, unit := range []time.Duration{time.Minute, time.Hour}[:len(parts)-1] {
+			count, err := strconv.ParseUint(parts[len(parts)-2-n], 10, 32)
+			if err != nil {
+				return 0, fmt.Errorf("invalid clock duration %s: %w", value, err)
+			}
+			part, err := scaleDuration(float64(count), unit)
+			if err != nil {
+				return 0, err
+			}
+			if duration, err = addDuration(duration, part); err != nil {
+				return 0, err
+			}
+		}
+		if negative {
+			duration = -duration
+		}
+		return duration, nil
+	}
+
+	if match := isoDurationPattern.FindStringSubmatch(value); match != nil && value != "P" && !strings.HasSuffix(value, "T") {
+		var duration time.Duration
+		for n, unit := range []time.Duration{24 * time.Hour, time.Hour, time.Minute} {
+			if match[n+2] != "" {
+				count, err := strconv.ParseInt(match[n+2], 10, 64)
+				if err != nil {
+					return 0, err
+				}
+				part, err := scaleDuration(float64(count), unit)
+				if err != nil {
+					return 0, err
+				}
+				if duration, err = addDuration(duration, part); err != nil {
+					return 0, err
+				}
+			}
+		}
+		if match[5] != "" {
+			seconds, err := strconv.ParseFloat(match[5], 64)
+			if err != nil {
+				return 0, err
+			}
+			part, err := scaleDuration(seconds, time.Second)
+			if err != nil {
+				return 0, err
+			}
+			if duration, err = addDuration(duration, part); err != nil {
+				return 0, err
+			}
+		}
+		if match[1] != "" {
+			duration = -duration
+		}
+		return duration, nil
+	}
+
+	return time.ParseDuration(value)
+}
+
+// formats a duration as an ISO 8601 duration such as PT1H2M3.5S
+func isoDuration(duration time.Duration) string {
+	var iso strings.Builder
+	if duration < 0 {
+		iso.WriteByte('-')
+		duration = -duration
+	}
+	iso.WriteString("PT")
+	if hours := duration / time.Hour; hours > 0 {
+		fmt.Fprintf(&iso, "%dH", hours)
+		duration -= hours * time.Hour
+	}
+	if minutes := duration / time.Minute; minutes > 0 {
+		fmt.Fprintf(&iso, "%dM", minutes)
+		duration -= minutes * time.Minute
+	}
+	if duration > 0 || iso.Len() <= 3 {
+		iso.WriteString(strconv.FormatFloat(duration.Seconds(), 'f', -1, 64))
+		iso.WriteByte('S')
+	}
+	return iso.String()
+}
+
+// converts a number of time units to a duration rounded to the nanosecond
+func unitDuration(value float64, unit string) (time.Duration, error) {
+	var scale time.Duration
+	switch unit {
+	case "", "s":
+		scale = time.Second
+	case "ms":
+		scale = time.Millisecond
+	case "us":
+		scale = time.Microsecond
+	case "ns":
+		scale = time.Nanosecond
+	default:
+		return 0, fmt.Errorf("invalid time unit: %s", unit)
+	}
+
+	return scaleDuration(value, scale)
+}
+
+// multiplies a duration by a number rounded to the nanosecond. Results outside of the
+// range of a duration (about 292 years) fail instead of wrapping
+func scaleDuration(value float64, scale time.Duration) (time.Duration, error) {
+	nanoseconds := math.Round(value * float64(scale))
+	if math.IsNaN(nanoseconds) || nanoseconds >= math.MaxInt64 || nanoseconds < math.MinInt64 {
+		return 0, fmt.Errorf("value (%g) is out of the range of a duration", value)
+	}
+	return time.Duration(nanoseconds), nil
+}
+
+// adds two durations. Results outside of the range of a duration fail instead of wrapping
+func addDuration(duration time.Duration, part time.Duration) (time.Duration, error) {
+	sum := duration + part
+	if (part > 0 && sum < duration) || (part < 0 && sum > duration) {
+		return 0, fmt.Errorf("duration %s plus %s is out of the range of a duration", duration, part)
+	}
+	return sum, nil
+}
+
+// reads a bool using strconv.ParseBool and the TrueValues and FalseValues of the options.
+//
+// ok is false if the value is not recognized
+func readBool(value string, options *DataTypeOptions) (data bool, ok bool) {
+	value = strings.TrimSpace(value)
+	data, err := strconv.ParseBool(value)
+	if err == nil {
+		return data, true
+	}
+
+	trueValues, falseValues := options.TrueValues, options.FalseValues
+	if len(trueValues) == 0 && len(falseValues) == 0 {
+		trueValues, falseValues = []string{"ok"}, []string{"ng"}
+	}
+
+	for _, trueValue := range trueValues {
+		if strings.EqualFold(value, trueValue) {
+			return true, true
+		}
+	}
+	for _, falseValue := range falseValues {
+		if strings.EqualFold(value, falseValue) {
+			return false, true
+		}
+	}
+	return false, false
+}
+
+// applies the numeric options to a value so it can be parsed by strconv.
+//
+// percent is true if the value was a percentage that must be divided by 100
+func normalizeNumber(value string, options *DataTypeOptions) (number string, percent bool, err error) {
+	number = value
+	if options.StripPattern != "" {
+		regex, err := compileRegexp(options.StripPattern)
+		if err != nil {
+			return "", false, err
+		}
+		number = strings.TrimSpace(regex.ReplaceAllString(number, ""))
+	}
+	if isSet(options.PercentToFraction) && strings.HasSuffix(number, "%") {
+		number = strings.TrimSpace(strings.TrimSuffix(number, "%"))
+		percent = true
+	}
+	if options.ThousandsSeparator != "" {
+		number = strings.ReplaceAll(number, options.ThousandsSeparator, "")
+	}
+	if options.DecimalSeparator != "" && options.DecimalSeparator != "." {
+		number = strings.ReplaceAll(number, options.DecimalSeparator, ".")
+	}
+	return number, percent, nil
+}
+
+// applies the Scale, Offset, unit conversion and RoundDigits of the options in that order
+// to a number. Values which are not numbers are returned as is.
+//
+// DataTypeInt64 results stay int64 if the transformed value is a whole number, such as
+// when RoundDigits is 0, and use the NumberFormat of the options otherwise
+func transformNumber(data any, options *DataTypeOptions) (any, error) {
+	var val float64
+	var err error
+	switch number := data.(type) {
+	case int64:
+		val = float64(number)
+	case Float64:
+		val = float64(number)
+	case Decimal:
+		val, err = number.Float64()
+	case json.Number:
+		val, err = number.Float64()
+	default:
+		return data, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	if options.Scale != nil {
+		val *= *options.Scale
+	}
+	if options.Offset != nil {
+		val += *options.Offset
+	}
+	if options.ToUnit != "" {
+		val, err = convertUnit(val, options.FromUnit, options.ToUnit)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	text := strconv.FormatFloat(val, 'f', -1, 64)
+	if options.RoundDigits != nil {
+		digits := *options.RoundDigits
+		scale := math.Pow10(digits)
+		val = math.Round(val*scale) / scale
+		text = strconv.FormatFloat(val, 'f', max(digits, 0), 64)
+	}
+
+	if _, ok := data.(int64); ok && val == math.Trunc(val) && math.Abs(val) < math.MaxInt64 {
+		return int64(val), nil
+	}
+	return formatNumber(text, text, val, false, options)
+}
+
+var jsonNumberPattern = regexp.MustCompile(`^-?(0|[1-9][0-9]*)(\.[0-9]+)?([eE][+-]?[0-9]+)?$`)
+
+// converts a parsed float to the NumberFormat of the options.
+//
+// number is the normalized text of value which is kept by the decimal formats
+// when it is valid JSON
+func formatNumber(value string, number string, val float64, percent bool, options *DataTypeOptions) (any, error) {
+	if math.IsNaN(val) || math.IsInf(val, 0) {
+		switch options.NonFinite {
+		case NonFiniteNull:
+			return nil, nil
+		case NonFiniteString:
+			return value, nil
+		case NonFiniteError:
+			return nil, fmt.Errorf("value (%s) is not a finite number", value)
+		default:
+			return nil, fmt.Errorf("invalid non finite policy: %s", options.NonFinite)
+		}
+	}
+
+	if percent {
+		val /= 100
+		number = strconv.FormatFloat(val, 'g', -1, 64)
+	}
+	if options.NumberFormat != NumberFormatFloat && !jsonNumberPattern.MatchString(number) {
+		number = strings.TrimPrefix(number, "+")
+		if !jsonNumberPattern.MatchString(number) {
+			number = strconv.FormatFloat(val, 'g', -1, 64)
+		}
+	}
+
+	switch options.NumberFormat {
+	case NumberFormatFloat:
+		return Float64(val), nil
+	case NumberFormatDecimal:
+		return Decimal(number), nil
+	case NumberFormatJSONNumber:
+		return json.Number(number), nil
+	default:
+		return nil, fmt.Errorf("invalid number format: %s", options.NumberFormat)
+	}
+}
+
+func (dt *DataType) readNumber(value string, defaultToFloat bool, options *DataTypeOptions) (result any, err error) {
+	number, percent, err := normalizeNumber(value, options)
+	if err != nil {
+		return nil, err
+	}
+
+	if (!defaultToFloat || isSet(options.KeepIntegers)) && !percent {
 		// check for integer
-		result, err = strconv.ParseInt(value, 10, 64)
+		result, err = strconv.ParseInt(number, 10, 64)
 		if err == nil {
 			return result.(int64), nil
 		}
 	}
 
 	// check for float
-	result, err = strconv.ParseFloat(value, 64)
+	val, err := strconv.ParseFloat(number, 64)
 	if err == nil {
-		return Float64(result.(float64)), nil
+		return formatNumber(value, number, val, percent, options)
 	}
 
 	// Unable to convert to number
@@ -117,24 +835,61 @@ func (dt *DataType) readNumber(value string, defaultToFloat bool) (result any, e
 }
 
 // Sets the data to the right type you must assert the type of data
-func (dt *DataType) readAuto(value string) any {
+func (dt *DataType) readAuto(value string, options *DataTypeOptions) any {
 	// check if blank
 	if value == "" {
 		return nil
 	}
 
 	// check for number
-	result, err := dt.readNumber(value, true)
+	result, err := dt.readNumber(value, true, options)
 	if err == nil {
 		return result
 	}
 
 	// check for bool
-	result, err = strconv.ParseBool(value)
-	if err == nil {
-		return result.(bool)
+	if data, ok := readBool(value, options); ok {
+		return data
 	}
 
 	// default to string
 	return value
 }
+
+// Finds a single data type that at least threshold of the non blank values can be read as
+// using the options.
+//
+// The data type matching the most values is used. Ties go to the most specific data type
+// so a column of integers is DataTypeInt64. If none match DataTypeString is returned with
+// the fraction of values matched by the closest data type.
+func inferDataType(values []string, threshold float64, options *DataTypeOptions) (DataType, float64) {
+	candidates := []DataType{DataTypeInt64, DataTypeFloat64, DataTypeBool, DataTypeDateTimeStyle0, DataTypeDateTimeStyle1}
+	matches := make([]int, len(candidates))
+	total := 0
+	for _, value := range values {
+		if strings.TrimSpace(value) == "" {
+			continue
+		}
+		total++
+		for n, candidate := range candidates {
+			if data, err := candidate.ReadWithOptions(value, options); err == nil && data != nil {
+				matches[n]++
+			}
+		}
+	}
+	if total == 0 {
+		return DataTypeAuto, 0
+	}
+
+	// candidates are ordered from most to least specific so only a higher confidence replaces the best
+	best, bestConfidence := DataTypeString, 0.0
+	for n, candidate := range candidates {
+		if confidence := float64(matches[n]) / float64(total); confidence > bestConfidence {
+			best, bestConfidence = candidate, confidence
+		}
+	}
+	if bestConfidence < threshold {
+		return DataTypeString, bestConfidence
+	}
+	return best, bestConfidence
+}
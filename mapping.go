@@ -0,0 +1,123 @@
+package csvParse
+
+import (
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+)
+
+// Translates raw values such as machine state codes to labels before they are read
+type ValueMapping struct {
+	Values map[string]string // Labels keyed by raw value. Used before the values of File
+
+	// Path of a reference csv with the raw values in the first column and the labels in
+	// the second. The file is loaded once and reloaded when its modification time or size changes.
+	// Changes are checked at most once per mappingCheckInterval
+	File          string
+	FileHasHeader bool // If true the first row of File is skipped
+
+	Unmapped UnmappedPolicy // Handling of values without a label. Defaults to UnmappedKeep
+}
+
+// Handling of values that are not in a ValueMapping
+type UnmappedPolicy string
+
+const (
+	UnmappedKeep  UnmappedPolicy = ""      // Reads the raw value with the data type
+	UnmappedNull  UnmappedPolicy = "null"  // Reads the value as null
+	UnmappedError UnmappedPolicy = "error" // Fails the conversion
+)
+
+var mappingFiles sync.Map // cache of loaded reference files keyed by mappingFile
+
+const mappingCheckInterval = time.Second // minimum time between checks of a reference file for changes
+
+type mappingFile struct {
+	path      string
+	hasHeader bool
+}
+
+// labels of a reference file and the state of the file when they were loaded
+type mappingLabels struct {
+	modTime time.Time
+	size    int64
+	labels  map[string]string
+	checked atomic.Int64 // unix nanoseconds of the last check for changes
+}
+
+// finds the label of a value. Values are compared after removing surrounding whitespace.
+//
+// mapped is false if the value should be read with the data type
+func (m *ValueMapping) lookup(value string) (label any, mapped bool, err error) {
+	key := strings.TrimSpace(value)
+	if label, exists := m.Values[key]; exists {
+		return label, true, nil
+	}
+
+	if m.File != "" {
+		labels, err := loadMappingFile(m.File, m.FileHasHeader)
+		if err != nil {
+			return nil, false, err
+		}
+		if label, exists := labels[key]; exists {
+			return label, true, nil
+		}
+	}
+
+	switch m.Unmapped {
+	case UnmappedKeep:
+		return nil, false, nil
+	case UnmappedNull:
+		return nil, true, nil
+	case UnmappedError:
+		return nil, false, fmt.Errorf("value (%s) has no mapping", value)
+	default:
+		return nil, false, fmt.Errorf("invalid unmapped policy: %s", m.Unmapped)
+	}
+}
+
+// loads the labels of a reference csv and reuses them for later calls with the same file
+// until the file is modified. The file is only checked for changes once per mappingCheckInterval
+func loadMappingFile(path string, hasHeader bool) (map[string]string, error) {
+	key := mappingFile{path: path, hasHeader: hasHeader}
+	cached, exists := mappingFiles.Load(key)
+	now := time.Now()
+	if exists && now.UnixNano()-cached.(*mappingLabels).checked.Load() < int64(mappingCheckInterval) {
+		return cached.(*mappingLabels).labels, nil
+	}
+
+	info, err := os.Stat(path)
+	if err != nil {
+		return nil, fmt.Errorf("error loading mapping file: %w", err)
+	}
+	if exists {
+		loaded := cached.(*mappingLabels)
+		if loaded.modTime.Equal(info.ModTime()) && loaded.size == info.Size() {
+			loaded.checked.Store(now.UnixNano())
+			return loaded.labels, nil
+		}
+	}
+
+	records, err := getRecords(path)
+	if err != nil {
+		return nil, fmt.Errorf("error loading mapping file: %w", err)
+	}
+	if hasHeader {
+		records = records[1:]
+	}
+
+	labels := make(map[string]string, len(records))
+	for n, record := range records {
+		if len(record) < 2 {
+			return nil, fmt.Errorf("mapping file %s row %d has less than 2 columns", path, n)
+		}
+		labels[strings.TrimSpace(record[0])] = strings.TrimSpace(record[1])
+	}
+	loaded := &mappingLabels{modTime: info.ModTime(), size: info.Size(), labels: labels}
+	loaded.checked.Store(now.UnixNano())
+	mappingFiles.Store(key, loaded)
+	return labels, nil
+}
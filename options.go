@@ -0,0 +1,496 @@
+package csvParse
+
+import (
+	"fmt"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Parameters used when reading a value with a DataType
+//
+// Options can be set on the Csv, a table, a table column, a cell or a concat
+// cell. Fields that are set override the options of the parent. Pointer fields
+// override when not nil so a child can turn off or zero a value set by a parent.
+type DataTypeOptions struct {
+	// Layouts tried in order by DataTypeDateTime. Layouts containing % are
+	// strftime style (%Y-%m-%d %H:%M:%S) and all others are Go layouts
+	Layouts []string
+
+	Location      string              // IANA name of the location of times without an offset. Uses the local time zone if blank
+	AmbiguousTime AmbiguousTimePolicy // Handling of wall times repeated or skipped by daylight saving changes
+
+	TimeUnit        string // Unit of DataTypeEpoch, DataTypeTimeOffset and numeric DataTypeDuration values. One of s, ms, us or ns. Defaults to s
+	ExcelDateSystem string // Date system of DataTypeExcelSerial values. Either 1900 or 1904. Defaults to 1900
+	OffsetField     string // Name of the TimeField that DataTypeTimeOffset values are added to
+
+	// Output format of times. One of the TimeFormat constants or a custom Go or
+	// strftime style layout. Defaults to TimeFormatRFC3339
+	TimeFormat string
+	TimeUTC    *bool // If true times are converted to UTC before they are formatted
+
+	DurationFormat DurationFormat // Output of DataTypeDuration. Defaults to DurationFormatSeconds
+
+	// Numeric options used by DataTypeInt64, DataTypeFloat64 and DataTypeAuto
+	DecimalSeparator   string // Defaults to .
+	ThousandsSeparator string // Removed before parsing. Not used if blank
+	StripPattern       string // Regex of text removed before parsing such as a unit suffix (\s*sec$)
+	PercentToFraction  *bool  // If true values ending in % are divided by 100. Not used by DataTypeInt64
+
+	// Linear transform and unit conversion of numbers. Applied as value*Scale + Offset, then
+	// converted from FromUnit to ToUnit, then rounded to RoundDigits decimal places
+	Scale       *float64 // Not used if nil
+	Offset      *float64 // Not used if nil
+	FromUnit    string   // Defaults to the unit of the column if the table has a UnitRow
+	ToUnit      string   // Not used if blank
+	RoundDigits *int     // Not used if nil
+
+	NumberFormat NumberFormat    // Output of DataTypeFloat64 and DataTypeAuto numbers. Defaults to NumberFormatFloat
+	KeepIntegers *bool           // If true DataTypeAuto reads integers as int64 instead of floats
+	NonFinite    NonFinitePolicy // Handling of NaN and Inf which cannot be marshaled to JSON. Defaults to NonFiniteNull
+
+	// Names of the bits of DataTypeInt64, DataTypeIntLiteral and DataTypeHex values keyed by bit
+	// number (0 is the least significant bit). If set the value is expanded into an object of
+	// bools keyed by name
+	Bitfield      map[int]string
+	BitfieldFlags *bool // If true the Bitfield expands into a list of the names of the set bits instead
+
+	ListSeparator string    // Separator of DataTypeList values. Defaults to ;
+	ListElement   *DataType // Data type of the elements of DataTypeList values. Defaults to DataTypeAuto
+
+	Mapping *ValueMapping // Translates raw values to labels before they are read. Mapped labels are not converted
+
+	RawOutput RawOutput // Output of the source text next to each typed value. Not used if blank
+	RawSuffix string    // Suffix of the key of the source text used by RawOutputSuffix. Defaults to _raw
+
+	// Handling of values which fail to convert. Defaults to ErrorPolicyFail except in tables
+	// with InferColumnDataTypes which default to ErrorPolicyNull
+	OnError ErrorPolicy
+
+	// Values which are read as null before they are converted such as N/A or -1.
+	// Values are compared after removing surrounding whitespace. Replaces the list of the parent
+	NullValues []string
+
+	// Vocabulary used by DataTypeBool and DataTypeAuto in addition to the values
+	// accepted by strconv.ParseBool. Compared without case. Defaults to ok and ng
+	TrueValues  []string
+	FalseValues []string
+	StrictBool  *bool // If true DataTypeBool fails on values that are not blank or in the vocabulary instead of returning null
+
+	baseTimes map[string]time.Time // parsed TimeFields keyed by name
+}
+
+const (
+	TimeFormatRFC3339     = "rfc3339"     // 2006-01-02T15:04:05Z07:00
+	TimeFormatRFC3339Nano = "rfc3339nano" // 2006-01-02T15:04:05.999999999Z07:00
+	TimeFormatEpochMillis = "epochMillis" // Milliseconds since 1970-01-01 UTC as a number
+)
+
+// Output of durations read by DataTypeDuration
+type DurationFormat string
+
+const (
+	DurationFormatSeconds DurationFormat = ""             // Seconds as a number in the NumberFormat of the options
+	DurationFormatMillis  DurationFormat = "milliseconds" // Whole milliseconds as an int64
+	DurationFormatISO8601 DurationFormat = "iso8601"      // ISO 8601 duration string such as PT1M2.5S
+)
+
+// Output of numbers read by DataTypeFloat64 and DataTypeAuto
+type NumberFormat string
+
+const (
+	NumberFormatFloat      NumberFormat = ""           // Float64 which always marshals with a decimal point (4.80 becomes 4.8)
+	NumberFormatDecimal    NumberFormat = "decimal"    // Decimal which keeps the digits of the source (4.80 stays 4.80)
+	NumberFormatJSONNumber NumberFormat = "jsonNumber" // json.Number containing the source text (4 stays 4)
+)
+
+// Handling of values which fail to convert to their data type. Every policy except
+// ErrorPolicyFail adds a Warning to the Report
+type ErrorPolicy string
+
+const (
+	ErrorPolicyFail    ErrorPolicy = "fail"    // Fails the parse
+	ErrorPolicyNull    ErrorPolicy = "null"    // Reads the value as null
+	ErrorPolicyString  ErrorPolicy = "string"  // Keeps the source text as a string
+	ErrorPolicyDropRow ErrorPolicy = "dropRow" // Leaves the row out of the table. Cells outside of tables are read as null
+)
+
+// Output of the source text of a value for auditing conversions
+type RawOutput string
+
+const (
+	RawOutputSuffix RawOutput = "suffix" // Adds the source text under the name of the value with the RawSuffix appended
+	RawOutputObject RawOutput = "object" // Replaces the value with an object holding the value and the source text as raw
+)
+
+// Handling of NaN and Inf values
+type NonFinitePolicy string
+
+const (
+	NonFiniteNull   NonFinitePolicy = ""       // Reads the value as null
+	NonFiniteString NonFinitePolicy = "string" // Keeps the value as a string
+	NonFiniteError  NonFinitePolicy = "error"  // Fails the conversion
+)
+
+// Handling of wall times that occur twice or not at all due to daylight saving time changes
+type AmbiguousTimePolicy string
+
+const (
+	AmbiguousTimeDefault  AmbiguousTimePolicy = ""         // Uses the behavior of time.ParseInLocation
+	AmbiguousTimeEarliest AmbiguousTimePolicy = "earliest" // Uses the earliest instant the wall time can represent
+	AmbiguousTimeLatest   AmbiguousTimePolicy = "latest"   // Uses the latest instant the wall time can represent
+	AmbiguousTimeError    AmbiguousTimePolicy = "error"    // Fails the conversion
+)
+
+// returns a copy of the options with the fields set in override replacing the current values
+func (o *DataTypeOptions) merge(override *DataTypeOptions) *DataTypeOptions {
+	merged := &DataTypeOptions{}
+	if o != nil {
+		*merged = *o
+	}
+	if override == nil {
+		return merged
+	}
+
+	if len(override.Layouts) > 0 {
+		merged.Layouts = override.Layouts
+	}
+	if override.Location != "" {
+		merged.Location = override.Location
+	}
+	if override.AmbiguousTime != "" {
+		merged.AmbiguousTime = override.AmbiguousTime
+	}
+	if override.TimeUnit != "" {
+		merged.TimeUnit = override.TimeUnit
+	}
+	if override.ExcelDateSystem != "" {
+		merged.ExcelDateSystem = override.ExcelDateSystem
+	}
+	if override.OffsetField != "" {
+		merged.OffsetField = override.OffsetField
+	}
+	if override.TimeFormat != "" {
+		merged.TimeFormat = override.TimeFormat
+	}
+	if override.TimeUTC != nil {
+		merged.TimeUTC = override.TimeUTC
+	}
+	if override.DurationFormat != "" {
+		merged.DurationFormat = override.DurationFormat
+	}
+	if override.DecimalSeparator != "" {
+		merged.DecimalSeparator = override.DecimalSeparator
+	}
+	if override.ThousandsSeparator != "" {
+		merged.ThousandsSeparator = override.ThousandsSeparator
+	}
+	if override.StripPattern != "" {
+		merged.StripPattern = override.StripPattern
+	}
+	if override.PercentToFraction != nil {
+		merged.PercentToFraction = override.PercentToFraction
+	}
+	if override.Scale != nil {
+		merged.Scale = override.Scale
+	}
+	if override.Offset != nil {
+		merged.Offset = override.Offset
+	}
+	if override.FromUnit != "" {
+		merged.FromUnit = override.FromUnit
+	}
+	if override.ToUnit != "" {
+		merged.ToUnit = override.ToUnit
+	}
+	if override.RoundDigits != nil {
+		merged.RoundDigits = override.RoundDigits
+	}
+	if override.NumberFormat != "" {
+		merged.NumberFormat = override.NumberFormat
+	}
+	if override.KeepIntegers != nil {
+		merged.KeepIntegers = override.KeepIntegers
+	}
+	if override.NonFinite != "" {
+		merged.NonFinite = override.NonFinite
+	}
+	if len(override.Bitfield) > 0 {
+		merged.Bitfield = override.Bitfield
+	}
+	if override.BitfieldFlags != nil {
+		merged.BitfieldFlags = override.BitfieldFlags
+	}
+	if override.ListSeparator != "" {
+		merged.ListSeparator = override.ListSeparator
+	}
+	if override.ListElement != nil {
+		merged.ListElement = override.ListElement
+	}
+	if override.Mapping != nil {
+		merged.Mapping = override.Mapping
+	}
+	if override.OnError != "" {
+		merged.OnError = override.OnError
+	}
+	if override.RawOutput != "" {
+		merged.RawOutput = override.RawOutput
+	}
+	if override.RawSuffix != "" {
+		merged.RawSuffix = override.RawSuffix
+	}
+	if len(override.NullValues) > 0 {
+		merged.NullValues = override.NullValues
+	}
+	if len(override.TrueValues) > 0 || len(override.FalseValues) > 0 {
+		merged.TrueValues = override.TrueValues
+		merged.FalseValues = override.FalseValues
+	}
+	if override.StrictBool != nil {
+		merged.StrictBool = override.StrictBool
+	}
+	return merged
+}
+
+var strftimeDirectives = map[byte]string{
+	'a': "Mon",
+	'A': "Monday",
+	'b': "Jan",
+	'B': "January",
+	'd': "02",
+	'e': "_2",
+	'f': "000000",
+	'F': "2006-01-02",
+	'H': "15",
+	'I': "03",
+	'j': "002",
+	'm': "01",
+	'M': "04",
+	'p': "PM",
+	'S': "05",
+	'T': "15:04:05",
+	'y': "06",
+	'Y': "2006",
+	'z': "-0700",
+	'Z': "MST",
+	'%': "%",
+}
+
+// converts a strftime style layout to a Go layout. Layouts without % are returned as is
+func goLayout(layout string) (string, error) {
+	if !strings.Contains(layout, "%") {
+		return layout, nil
+	}
+
+	var converted strings.Builder
+	for i := 0; i < len(layout); i++ {
+		if layout[i] != '%' {
+			converted.WriteByte(layout[i])
+			continue
+		}
+		if i+1 >= len(layout) {
+			return "", fmt.Errorf("layout (%s) ends with an incomplete directive", layout)
+		}
+		i++
+		directive, exists := strftimeDirectives[layout[i]]
+		if !exists {
+			return "", fmt.Errorf("unsupported directive %%%c in layout (%s)", layout[i], layout)
+		}
+		converted.WriteString(directive)
+	}
+	return converted.String(), nil
+}
+
+// checks if an optional flag of the options is true
+func isSet(flag *bool) bool {
+	return flag != nil && *flag
+}
+
+// checks if numbers are transformed after they are read
+func (o *DataTypeOptions) hasTransform() bool {
+	return o.Scale != nil || o.Offset != nil || o.ToUnit != "" || o.RoundDigits != nil
+}
+
+// applies the OnError policy of the options to a conversion error. The error is returned
+// as is for ErrorPolicyFail, otherwise the warning is added to the report and the value
+// to use in place of the failed conversion is returned
+func (o *DataTypeOptions) recoverError(err error, raw string, warning Warning, report *Report) (any, error) {
+	var data any
+	switch o.OnError {
+	case "", ErrorPolicyFail:
+		return nil, err
+	case ErrorPolicyNull, ErrorPolicyDropRow:
+	case ErrorPolicyString:
+		data = raw
+	default:
+		return nil, fmt.Errorf("invalid error policy: %s", o.OnError)
+	}
+
+	warning.Raw = raw
+	warning.Reason = err.Error()
+	report.addWarning(warning)
+	return data, nil
+}
+
+// stores a value and its source text in target using the RawOutput of the options
+func (o *DataTypeOptions) setValue(target map[string]any, name string, data any, raw string) error {
+	switch o.RawOutput {
+	case "":
+		target[name] = data
+	case RawOutputSuffix:
+		target[name] = data
+		target[o.rawName(name)] = raw
+	case RawOutputObject:
+		target[name] = rawObject(data, raw)
+	default:
+		return fmt.Errorf("invalid raw output: %s", o.RawOutput)
+	}
+	return nil
+}
+
+// pairs a value with its source text for RawOutputObject
+func rawObject(data any, raw string) map[string]any {
+	return map[string]any{"value": data, "raw": raw}
+}
+
+// returns the key of the source text of a value used by RawOutputSuffix
+func (o *DataTypeOptions) rawName(name string) string {
+	if o.RawSuffix == "" {
+		return name + "_raw"
+	}
+	return name + o.RawSuffix
+}
+
+// checks if a value is one of the null values
+func (o *DataTypeOptions) isNull(value string) bool {
+	return len(o.NullValues) > 0 && slices.Contains(o.NullValues, strings.TrimSpace(value))
+}
+
+var (
+	locations sync.Map // cache of loaded locations keyed by name
+	regexps   sync.Map // cache of compiled regexps keyed by pattern
+)
+
+// compiles a regexp once and reuses it for later calls with the same pattern
+func compileRegexp(pattern string) (*regexp.Regexp, error) {
+	if regex, exists := regexps.Load(pattern); exists {
+		return regex.(*regexp.Regexp), nil
+	}
+
+	regex, err := regexp.Compile(pattern)
+	if err != nil {
+		return nil, fmt.Errorf("error compiling regexp %s: %w", pattern, err)
+	}
+	regexps.Store(pattern, regex)
+	return regex, nil
+}
+
+// loads a location by its IANA name. A blank name is the local time zone
+func loadLocation(name string) (*time.Location, error) {
+	if name == "" {
+		return time.Local, nil
+	}
+	if location, exists := locations.Load(name); exists {
+		return location.(*time.Location), nil
+	}
+
+	location, err := time.LoadLocation(name)
+	if err != nil {
+		return nil, fmt.Errorf("error loading location %s: %w", name, err)
+	}
+	locations.Store(name, location)
+	return location, nil
+}
+
+// parses a time using the location and ambiguous time policy of the options.
+//
+// Values which contain their own offset are returned as parsed
+func parseTime(layout string, value string, options *DataTypeOptions) (time.Time, error) {
+	location, err := loadLocation(options.Location)
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	parsed, err := time.ParseInLocation(layout, value, location)
+	if err != nil || options.AmbiguousTime == AmbiguousTimeDefault || layoutHasZone(layout) {
+		return parsed, err
+	}
+
+	wall, err := time.ParseInLocation(layout, value, time.UTC)
+	if err != nil {
+		return time.Time{}, err
+	}
+	return resolveWallTime(wall, location, options.AmbiguousTime)
+}
+
+// reports whether a time layout contains a zone name or offset field
+func layoutHasZone(layout string) bool {
+	return strings.Contains(layout, "MST") || strings.Contains(layout, "Z07") || strings.Contains(layout, "-07")
+}
+
+// finds the instant for a wall time (stored as UTC) in a location using the policy
+// when the wall time is repeated or skipped by a daylight saving time change
+func resolveWallTime(wall time.Time, location *time.Location, policy AmbiguousTimePolicy) (time.Time, error) {
+	if policy == AmbiguousTimeDefault {
+		return time.Date(wall.Year(), wall.Month(), wall.Day(), wall.Hour(), wall.Minute(), wall.Second(), wall.Nanosecond(), location), nil
+	}
+
+	// the offsets in effect a day before and after cover any single transition
+	_, offsetBefore := wall.Add(-24 * time.Hour).In(location).Zone()
+	_, offsetAfter := wall.Add(24 * time.Hour).In(location).Zone()
+
+	earliest := wall.Add(-time.Duration(max(offsetBefore, offsetAfter)) * time.Second).In(location)
+	latest := wall.Add(-time.Duration(min(offsetBefore, offsetAfter)) * time.Second).In(location)
+	earliestValid := sameWallTime(earliest, wall)
+	latestValid := sameWallTime(latest, wall)
+
+	switch {
+	case earliest.Equal(latest) || (earliestValid != latestValid):
+		if latestValid && !earliestValid {
+			return latest, nil
+		}
+		return earliest, nil
+	case policy == AmbiguousTimeError && earliestValid:
+		return time.Time{}, fmt.Errorf("wall time %s is ambiguous in %s", wall.Format(time.DateTime), location)
+	case policy == AmbiguousTimeError:
+		return time.Time{}, fmt.Errorf("wall time %s does not exist in %s", wall.Format(time.DateTime), location)
+	case policy == AmbiguousTimeLatest:
+		return latest, nil
+	case policy == AmbiguousTimeEarliest:
+		return earliest, nil
+	default:
+		return time.Time{}, fmt.Errorf("invalid ambiguous time policy: %s", policy)
+	}
+}
+
+// formats a time for output using the TimeFormat of the options
+func formatTime(t time.Time, options *DataTypeOptions) (any, error) {
+	if isSet(options.TimeUTC) {
+		t = t.UTC()
+	}
+
+	switch options.TimeFormat {
+	case "", TimeFormatRFC3339:
+		return t.Format(time.RFC3339), nil
+	case TimeFormatRFC3339Nano:
+		return t.Format(time.RFC3339Nano), nil
+	case TimeFormatEpochMillis:
+		return t.UnixMilli(), nil
+	default:
+		layout, err := goLayout(options.TimeFormat)
+		if err != nil {
+			return nil, fmt.Errorf("invalid time format: %w", err)
+		}
+		return t.Format(layout), nil
+	}
+}
+
+// checks if the wall clock of a time matches a wall time stored as UTC
+func sameWallTime(t time.Time, wall time.Time) bool {
+	year, month, day := t.Date()
+	wallYear, wallMonth, wallDay := wall.Date()
+	return year == wallYear && month == wallMonth && day == wallDay &&
+		t.Hour() == wall.Hour() && t.Minute() == wall.Minute() && t.Second() == wall.Second() && t.Nanosecond() == wall.Nanosecond()
+}
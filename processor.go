@@ -3,7 +3,11 @@ package csvParse
 import (
 	"encoding/json"
 	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
 	"strings"
+	"sync"
 )
 
 type ProcessorType int
@@ -15,35 +19,182 @@ const (
 	ProcessorTypeReplaceCell
 	ProcessorTypeTransposeRow
 	ProcessorTypeRemoveCellLeft
+	ProcessorTypeFillDown
+	ProcessorTypeDeleteRows
+	ProcessorTypeDeleteColumns
+	ProcessorTypeRegexReplace
 
 	ProcessorTypeEnd // Used to confirm all types are accounted for
 )
 
+// Registry of the processors which can be loaded by name
+var processors = struct {
+	sync.RWMutex
+	types     map[string]ProcessorType
+	names     map[ProcessorType]string
+	factories map[ProcessorType]func() Processor
+	next      ProcessorType // type assigned to the next processor registered with RegisterProcessor
+}{
+	types:     make(map[string]ProcessorType),
+	names:     make(map[ProcessorType]string),
+	factories: make(map[ProcessorType]func() Processor),
+	next:      ProcessorTypeEnd + 1,
+}
+
+func init() {
+	builtIns := []struct {
+		processorType ProcessorType
+		name          string
+		factory       func() Processor
+	}{
+		{ProcessorTypeMergeColumns, "mergeColumns", func() Processor { return &ProcessorMergeColumns{} }},
+		{ProcessorTypeMergeRows, "mergeRows", func() Processor { return &ProcessorMergeRows{} }},
+		{ProcessorTypeFillRight, "fillRight", func() Processor { return &ProcessorFillRight{} }},
+		{ProcessorTypeReplaceCell, "replaceCell", func() Processor { return &ProcessorReplaceCell{} }},
+		{ProcessorTypeTransposeRow, "transposeRow", func() Processor { return &ProcessorTransposeRow{} }},
+		{ProcessorTypeRemoveCellLeft, "removeCellLeft", func() Processor { return &ProcessorRemoveCellLeft{} }},
+		{ProcessorTypeFillDown, "fillDown", func() Processor { return &ProcessorFillDown{} }},
+		{ProcessorTypeDeleteRows, "deleteRows", func() Processor { return &ProcessorDeleteRows{} }},
+		{ProcessorTypeDeleteColumns, "deleteColumns", func() Processor { return &ProcessorDeleteColumns{} }},
+		{ProcessorTypeRegexReplace, "regexReplace", func() Processor { return &ProcessorRegexReplace{} }},
+	}
+	for _, builtIn := range builtIns {
+		if err := registerProcessor(builtIn.processorType, builtIn.name, builtIn.factory); err != nil {
+			panic(err)
+		}
+	}
+}
+
+// Registers a processor so it can be marshaled and loaded from json by name. The factory must
+// return a new pointer to the processor and the GetType method of the processor must return
+// the returned ProcessorType.
+//
+// Names are stored in configs so they must never change. Registering a name twice fails
+func RegisterProcessor(name string, factory func() Processor) (ProcessorType, error) {
+	processors.Lock()
+	defer processors.Unlock()
+
+	processorType := processors.next
+	if err := registerProcessorLocked(processorType, name, factory); err != nil {
+		return 0, err
+	}
+	processors.next++
+	return processorType, nil
+}
+
+func registerProcessor(processorType ProcessorType, name string, factory func() Processor) error {
+	processors.Lock()
+	defer processors.Unlock()
+	return registerProcessorLocked(processorType, name, factory)
+}
+
+// registers a processor. The lock of the registry must be held
+func registerProcessorLocked(processorType ProcessorType, name string, factory func() Processor) error {
+	if name == "" {
+		return fmt.Errorf("processor name cannot be blank")
+	} else if factory == nil {
+		return fmt.Errorf("factory for processor %s cannot be nil", name)
+	} else if _, exists := processors.types[name]; exists {
+		return fmt.Errorf("processor %s is already registered", name)
+	} else if existing, exists := processors.names[processorType]; exists {
+		return fmt.Errorf("processor type %d is already registered as %s", processorType, existing)
+	}
+
+	processors.types[name] = processorType
+	processors.names[processorType] = name
+	processors.factories[processorType] = factory
+	return nil
+}
+
+func (pt ProcessorType) String() string {
+	processors.RLock()
+	defer processors.RUnlock()
+	if name, exists := processors.names[pt]; exists {
+		return name
+	}
+	return "unknown"
+}
+
+// Marshals the processor type as its registered name
+func (pt ProcessorType) MarshalJSON() ([]byte, error) {
+	processors.RLock()
+	name, exists := processors.names[pt]
+	processors.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("unknown processor type: %d", pt)
+	}
+	return json.Marshal(name)
+}
+
+// Unmarshals the processor type from its registered name or from the integer used by
+// older configs for the built in processors
+func (pt *ProcessorType) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+
+	var value ProcessorType
+	if len(data) > 0 && data[0] == '"' {
+		var name string
+		if err := json.Unmarshal(data, &name); err != nil {
+			return fmt.Errorf("error unmarshaling processor type: %w", err)
+		}
+		processors.RLock()
+		processorType, exists := processors.types[name]
+		processors.RUnlock()
+		if !exists {
+			return fmt.Errorf("unknown processor type: %s", name)
+		}
+		value = processorType
+	} else {
+		var number int
+		if err := json.Unmarshal(data, &number); err != nil {
+			return fmt.Errorf("error unmarshaling processor type: %w", err)
+		}
+		value = ProcessorType(number)
+		if value < 0 || value >= ProcessorTypeEnd {
+			return fmt.Errorf("unknown processor type: %d", number)
+		}
+	}
+	*pt = value
+	return nil
+}
+
 func getProcessor(processorType ProcessorType, data json.RawMessage) (processor Processor, err error) {
-	switch processorType {
-	case ProcessorTypeMergeColumns:
-		processor = &ProcessorMergeColumns{}
-	case ProcessorTypeMergeRows:
-		processor = &ProcessorMergeRows{}
-	case ProcessorTypeFillRight:
-		processor = &ProcessorFillRight{}
-	case ProcessorTypeReplaceCell:
-		processor = &ProcessorReplaceCell{}
-	case ProcessorTypeTransposeRow:
-		processor = &ProcessorTransposeRow{}
-	case ProcessorTypeRemoveCellLeft:
-		processor = &ProcessorRemoveCellLeft{}
-	default:
-		return nil, fmt.Errorf("invalid type: %d", processorType)
+	processors.RLock()
+	factory, exists := processors.factories[processorType]
+	processors.RUnlock()
+	if !exists {
+		return nil, fmt.Errorf("invalid type: %s (%d)", processorType, processorType)
 	}
 
+	processor = factory()
+	if processor.GetType() != processorType {
+		return nil, fmt.Errorf("processor %s returns type %d instead of its registered type %d", processorType, processor.GetType(), processorType)
+	}
 	if err := json.Unmarshal(data, &processor); err != nil {
-		return nil, fmt.Errorf("unable to unmarshal processorType %d: %w", processor.GetType(), err)
+		return nil, fmt.Errorf("unable to unmarshal processorType %s: %w", processorType, err)
 	}
 
 	return processor, nil
 }
 
+// checks a processor is the one created by the factory registered for its type so it is
+// marshaled with the name it is loaded from
+func checkProcessorType(processor Processor) error {
+	processorType := processor.GetType()
+	processors.RLock()
+	factory, exists := processors.factories[processorType]
+	processors.RUnlock()
+	if !exists {
+		return fmt.Errorf("processor %T returns unregistered type %d", processor, processorType)
+	}
+	if registered := factory(); reflect.TypeOf(registered) != reflect.TypeOf(processor) {
+		return fmt.Errorf("processor %T returns type %s which is registered for %T", processor, processorType, registered)
+	}
+	return nil
+}
+
 type Processor interface {
 	GetName() string
 	Execute(records [][]string) ([][]string, error)
@@ -51,6 +202,12 @@ type Processor interface {
 	SetType()
 }
 
+// Processor which counts the rows or columns it removes so they can be added to the Report
+type CountingProcessor interface {
+	Processor
+	ExecuteWithCount(records [][]string) (result [][]string, removed int, err error)
+}
+
 // # Merge one or more columns together across defined rows
 //
 // Example:
@@ -318,6 +475,103 @@ func (a *ProcessorFillRight) Execute(records [][]string) ([][]string, error) {
 	return records, nil
 }
 
+// # Fills blank spaces using data above the blank row
+//
+// Example:
+//
+//	input := [][]string{
+//		{"Line", "Part", "Count"},
+//		{"L1", "A", "10"},
+//		{"", "B", "12"},
+//		{"", "", ""},
+//		{"L2", "C", "7"},
+//		{"", "D", "9"},
+//	}
+//	action:= &ProcessorFillDown{
+//	  Start:          Cell{Row: 1, Column: 0},
+//	  End:            Cell{Row: -1, Column: 1},
+//	  RequireRowData: true,
+//	}
+//	output := [][]string{
+//		{"Line", "Part", "Count"},
+//		{"L1", "A", "10"},
+//		{"L1", "B", "12"},
+//		{"", "", ""},
+//		{"L2", "C", "7"},
+//		{"L2", "D", "9"},
+//	}
+type ProcessorFillDown struct {
+	Type           ProcessorType
+	Name           string
+	Start          Cell // Blank cells in the first row of the range are not filled
+	End            Cell // Set Row = -1 if you want to do all rows, Set Column = -1 if you want to do all columns
+	RequireRowData bool // If true cells are only filled if another cell in the row is not blank
+	MaxDistance    int  // Maximum number of rows a value is copied below the row it was found in. Not limited if 0
+}
+
+func (a *ProcessorFillDown) GetName() string {
+	return a.Name
+}
+func (a *ProcessorFillDown) GetType() ProcessorType {
+	return ProcessorTypeFillDown
+}
+func (a *ProcessorFillDown) SetType() {
+	a.Type = a.GetType()
+}
+
+func (a *ProcessorFillDown) Execute(records [][]string) ([][]string, error) {
+	if a.Start.Row < 0 || a.Start.Column < 0 {
+		return nil, fmt.Errorf("neither start row (%d) nor column (%d) can be < 0", a.Start.Row, a.Start.Column)
+	} else if a.End.Row < a.Start.Row && !(a.End.Row < 0) {
+		return nil, fmt.Errorf("end row (%d) cannot be less than start row (%d) unless less than zero", a.End.Row, a.Start.Row)
+	} else if a.End.Column < a.Start.Column && !(a.End.Column < 0) {
+		return nil, fmt.Errorf("end column (%d) cannot be less than start column (%d) unless less than zero", a.End.Column, a.Start.Column)
+	} else if a.Start.Row > len(records) {
+		return nil, fmt.Errorf("start row (%d) cannot be greater than the length of records (%d)", a.Start.Row, len(records))
+	} else if a.End.Row > len(records) {
+		return nil, fmt.Errorf("end row (%d) cannot be greater than the length of records (%d)", a.End.Row, len(records))
+	} else if a.MaxDistance < 0 {
+		return nil, fmt.Errorf("max distance (%d) cannot be < 0", a.MaxDistance)
+	}
+
+	endRow := a.End.Row
+	if endRow < 0 {
+		endRow = len(records)
+	}
+	endColumn := a.End.Column
+
+	// number of rows each column has been filled since the last value keyed by column
+	distances := make(map[int]int)
+	for n := a.Start.Row; n < endRow; n++ {
+		if a.End.Column < 0 {
+			endColumn = len(records[n])
+		}
+		if endColumn > len(records[n]) {
+			return nil, fmt.Errorf("row %d: endColumn (%d) cannot be greater than the length of columns (%d)", n, endColumn, len(records[n]))
+		}
+
+		hasData := !a.RequireRowData || slices.ContainsFunc(records[n], func(value string) bool {
+			return strings.TrimSpace(value) != ""
+		})
+
+		for i := a.Start.Column; i < endColumn; i++ {
+			if strings.TrimSpace(records[n][i]) != "" {
+				distances[i] = 0
+				continue
+			} else if n == a.Start.Row || i >= len(records[n-1]) || !hasData {
+				continue
+			} else if a.MaxDistance > 0 && distances[i] >= a.MaxDistance {
+				continue
+			} else {
+				records[n][i] = records[n-1][i]
+				distances[i]++
+			}
+		}
+	}
+
+	return records, nil
+}
+
 // # Replaces a cell's value with another
 //
 // Example:
@@ -499,3 +753,318 @@ func (a *ProcessorRemoveCellLeft) Execute(records [][]string) ([][]string, error
 
 	return records, nil
 }
+
+// # Replaces the matches of a regex in every cell of a range
+//
+// The Replacement can reference capture groups with $1 or ${name}.
+//
+// Example:
+//
+//	input := [][]string{
+//		{"Cycle Time          ", "Speed [Up]", "Speed [Down]"},
+//		{"55.3", "0.148", "0.150"},
+//	}
+//	action:= &ProcessorRegexReplace{
+//		Start:       Cell{Row: 0, Column: 0},
+//		End:         Cell{Row: -1, Column: -1},
+//		Pattern:     `^\s*(.*?)\s*(\[Up\])?$`,
+//		Replacement: "$1",
+//		HeaderRows:  []int{0},
+//	}
+//	output := [][]string{
+//		{"Cycle Time", "Speed", "Speed [Down]"},
+//		{"55.3", "0.148", "0.150"},
+//	}
+type ProcessorRegexReplace struct {
+	Type        ProcessorType
+	Name        string
+	Start       Cell
+	End         Cell // Set Row = -1 if you want to do all rows, Set Column = -1 if you want to do all columns
+	Pattern     string
+	Replacement string
+	HeaderRows  []int // If set only these rows of the range are changed
+}
+
+func (a *ProcessorRegexReplace) GetName() string {
+	return a.Name
+}
+func (a *ProcessorRegexReplace) GetType() ProcessorType {
+	return ProcessorTypeRegexReplace
+}
+func (a *ProcessorRegexReplace) SetType() {
+	a.Type = a.GetType()
+}
+
+func (a *ProcessorRegexReplace) Execute(records [][]string) ([][]string, error) {
+	if a.Start.Row < 0 || a.Start.Column < 0 {
+		return nil, fmt.Errorf("neither start row (%d) nor column (%d) can be < 0", a.Start.Row, a.Start.Column)
+	} else if a.End.Row < a.Start.Row && !(a.End.Row < 0) {
+		return nil, fmt.Errorf("end row (%d) cannot be less than start row (%d) unless less than zero", a.End.Row, a.Start.Row)
+	} else if a.End.Column < a.Start.Column && !(a.End.Column < 0) {
+		return nil, fmt.Errorf("end column (%d) cannot be less than start column (%d) unless less than zero", a.End.Column, a.Start.Column)
+	} else if a.Start.Row > len(records) {
+		return nil, fmt.Errorf("start row (%d) cannot be greater than the length of records (%d)", a.Start.Row, len(records))
+	} else if a.End.Row > len(records) {
+		return nil, fmt.Errorf("end row (%d) cannot be greater than the length of records (%d)", a.End.Row, len(records))
+	} else if a.Pattern == "" {
+		return nil, fmt.Errorf("pattern cannot be blank")
+	}
+
+	regex, err := compileRegexp(a.Pattern)
+	if err != nil {
+		return nil, err
+	}
+
+	endRow := a.End.Row
+	if endRow < 0 {
+		endRow = len(records)
+	}
+	endColumn := a.End.Column
+
+	for n := a.Start.Row; n < endRow; n++ {
+		if len(a.HeaderRows) > 0 && !slices.Contains(a.HeaderRows, n) {
+			continue
+		}
+		if a.End.Column < 0 {
+			endColumn = len(records[n])
+		}
+		if endColumn > len(records[n]) {
+			return nil, fmt.Errorf("row %d: endColumn (%d) cannot be greater than the length of columns (%d)", n, endColumn, len(records[n]))
+		}
+
+		for i := a.Start.Column; i < endColumn; i++ {
+			records[n][i] = regex.ReplaceAllString(records[n][i], a.Replacement)
+		}
+	}
+
+	return records, nil
+}
+
+// # Deletes rows in a range which are blank or match a pattern
+//
+// Rows from StartRow up to but not including EndRow are checked. If neither Blank nor
+// Pattern is set every row in the range is deleted, otherwise rows matching either are.
+//
+// Example:
+//
+//	input := [][]string{
+//		{"Time", "Temp"},
+//		{"s", "°C"},
+//		{"0", "21.5"},
+//		{"", ""},
+//		{"1", "21.7"},
+//		{"Total", "43.2"},
+//	}
+//	action:= &ProcessorDeleteRows{
+//		StartRow: 2,
+//		EndRow:   -1,
+//		Blank:    true,
+//		Pattern:  "^Total$",
+//		Column:   0,
+//	}
+//	output := [][]string{
+//		{"Time", "Temp"},
+//		{"s", "°C"},
+//		{"0", "21.5"},
+//		{"1", "21.7"},
+//	}
+type ProcessorDeleteRows struct {
+	Type     ProcessorType
+	Name     string
+	StartRow int
+	EndRow   int    // Set < 0 to check all rows after StartRow
+	Blank    bool   // If true rows where every cell is blank are deleted
+	Pattern  string // Regex which deletes the rows where the cell in Column matches
+	Column   int    // Column checked by Pattern. Set < 0 to check every cell of the row
+}
+
+func (a *ProcessorDeleteRows) GetName() string {
+	return a.Name
+}
+func (a *ProcessorDeleteRows) GetType() ProcessorType {
+	return ProcessorTypeDeleteRows
+}
+func (a *ProcessorDeleteRows) SetType() {
+	a.Type = a.GetType()
+}
+
+func (a *ProcessorDeleteRows) Execute(records [][]string) ([][]string, error) {
+	records, _, err := a.ExecuteWithCount(records)
+	return records, err
+}
+
+func (a *ProcessorDeleteRows) ExecuteWithCount(records [][]string) ([][]string, int, error) {
+	if a.StartRow < 0 {
+		return nil, 0, fmt.Errorf("start row (%d) cannot be < 0", a.StartRow)
+	} else if a.EndRow < a.StartRow && !(a.EndRow < 0) {
+		return nil, 0, fmt.Errorf("end row (%d) cannot be less than start row (%d) unless less than zero", a.EndRow, a.StartRow)
+	} else if a.StartRow > len(records) {
+		return nil, 0, fmt.Errorf("start row (%d) cannot be greater than the length of records (%d)", a.StartRow, len(records))
+	} else if a.EndRow > len(records) {
+		return nil, 0, fmt.Errorf("end row (%d) cannot be greater than the length of records (%d)", a.EndRow, len(records))
+	}
+
+	matcher, err := newCellMatcher(a.Blank, a.Pattern)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	endRow := a.EndRow
+	if endRow < 0 {
+		endRow = len(records)
+	}
+
+	new := make([][]string, 0, len(records))
+	new = append(new, records[:a.StartRow]...)
+	for n := a.StartRow; n < endRow; n++ {
+		if !matcher.matches(records[n], a.Column) {
+			new = append(new, records[n])
+		}
+	}
+	new = append(new, records[endRow:]...)
+
+	return new, len(records) - len(new), nil
+}
+
+// # Deletes columns in a range which are blank or match a pattern
+//
+// Columns from StartColumn up to but not including EndColumn are checked. If neither
+// Blank nor Pattern is set every column in the range is deleted, otherwise columns
+// matching either are. Cells missing from short rows are treated as blank.
+//
+// Example:
+//
+//	input := [][]string{
+//		{"Time", "", "Temp [Up]", "Note"},
+//		{"0", "", "21.5", "ok"},
+//		{"1", "", "21.7"},
+//	}
+//	action:= &ProcessorDeleteColumns{
+//		StartColumn: 0,
+//		EndColumn:   -1,
+//		Blank:       true,
+//		Pattern:     "^Note$",
+//		Row:         0,
+//	}
+//	output := [][]string{
+//		{"Time", "Temp [Up]"},
+//		{"0", "21.5"},
+//		{"1", "21.7"},
+//	}
+type ProcessorDeleteColumns struct {
+	Type        ProcessorType
+	Name        string
+	StartColumn int
+	EndColumn   int    // Set < 0 to check all columns after StartColumn
+	Blank       bool   // If true columns where every cell is blank are deleted
+	Pattern     string // Regex which deletes the columns where the cell in Row matches
+	Row         int    // Row checked by Pattern. Set < 0 to check every cell of the column
+}
+
+func (a *ProcessorDeleteColumns) GetName() string {
+	return a.Name
+}
+func (a *ProcessorDeleteColumns) GetType() ProcessorType {
+	return ProcessorTypeDeleteColumns
+}
+func (a *ProcessorDeleteColumns) SetType() {
+	a.Type = a.GetType()
+}
+
+func (a *ProcessorDeleteColumns) Execute(records [][]string) ([][]string, error) {
+	records, _, err := a.ExecuteWithCount(records)
+	return records, err
+}
+
+func (a *ProcessorDeleteColumns) ExecuteWithCount(records [][]string) ([][]string, int, error) {
+	if a.StartColumn < 0 {
+		return nil, 0, fmt.Errorf("start column (%d) cannot be < 0", a.StartColumn)
+	} else if a.EndColumn < a.StartColumn && !(a.EndColumn < 0) {
+		return nil, 0, fmt.Errorf("end column (%d) cannot be less than start column (%d) unless less than zero", a.EndColumn, a.StartColumn)
+	} else if a.Pattern != "" && a.Row >= len(records) {
+		return nil, 0, fmt.Errorf("row (%d) cannot be greater than the length of records (%d)", a.Row, len(records))
+	}
+
+	matcher, err := newCellMatcher(a.Blank, a.Pattern)
+	if err != nil {
+		return nil, 0, err
+	}
+
+	width := 0
+	for _, record := range records {
+		width = max(width, len(record))
+	}
+	endColumn := a.EndColumn
+	if endColumn < 0 || endColumn > width {
+		endColumn = width
+	}
+
+	deleted := make(map[int]bool)
+	column := make([]string, len(records))
+	for i := a.StartColumn; i < endColumn; i++ {
+		for n, record := range records {
+			column[n] = ""
+			if i < len(record) {
+				column[n] = record[i]
+			}
+		}
+		if matcher.matches(column, a.Row) {
+			deleted[i] = true
+		}
+	}
+	if len(deleted) == 0 {
+		return records, 0, nil
+	}
+
+	new := make([][]string, len(records))
+	for n, record := range records {
+		new[n] = make([]string, 0, len(record))
+		for i, value := range record {
+			if !deleted[i] {
+				new[n] = append(new[n], value)
+			}
+		}
+	}
+
+	return new, len(deleted), nil
+}
+
+// selects the rows or columns deleted by ProcessorDeleteRows and ProcessorDeleteColumns
+type cellMatcher struct {
+	blank   bool
+	pattern *regexp.Regexp
+}
+
+func newCellMatcher(blank bool, pattern string) (*cellMatcher, error) {
+	matcher := &cellMatcher{blank: blank}
+	if pattern != "" {
+		var err error
+		matcher.pattern, err = compileRegexp(pattern)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return matcher, nil
+}
+
+// checks if the cells should be deleted. The pattern is checked against the cell at
+// index or every cell if index is < 0
+func (m *cellMatcher) matches(cells []string, index int) bool {
+	if !m.blank && m.pattern == nil {
+		return true
+	}
+
+	if m.blank && !slices.ContainsFunc(cells, func(value string) bool {
+		return strings.TrimSpace(value) != ""
+	}) {
+		return true
+	}
+
+	if m.pattern != nil {
+		if index < 0 {
+			return slices.ContainsFunc(cells, m.pattern.MatchString)
+		}
+		return index < len(cells) && m.pattern.MatchString(cells[index])
+	}
+	return false
+}
@@ -1,7 +1,10 @@
 package csvParse
 
 import (
+	"encoding/json"
 	"reflect"
+	"strconv"
+	"strings"
 	"testing"
 )
 
@@ -31,8 +34,12 @@ func TestProcessorType(t *testing.T) {
 		ProcessorTypeReplaceCell:    3,
 		ProcessorTypeTransposeRow:   4,
 		ProcessorTypeRemoveCellLeft: 5,
+		ProcessorTypeFillDown:       6,
+		ProcessorTypeDeleteRows:     7,
+		ProcessorTypeDeleteColumns:  8,
+		ProcessorTypeRegexReplace:   9,
 
-		ProcessorTypeEnd: 6,
+		ProcessorTypeEnd: 10,
 	}
 
 	if len(processorTypes)-1 != int(ProcessorTypeEnd) {
@@ -59,6 +66,99 @@ func TestProcessorType(t *testing.T) {
 	}
 }
 
+// Processor registered by the tests to check custom processors can be loaded by name
+type testProcessorUpper struct {
+	Type ProcessorType
+	Name string
+	Row  int
+}
+
+var testProcessorUpperType, testProcessorUpperErr = RegisterProcessor("testUpper", func() Processor { return &testProcessorUpper{} })
+
+func (a *testProcessorUpper) GetName() string {
+	return a.Name
+}
+
+func (a *testProcessorUpper) GetType() ProcessorType {
+	return testProcessorUpperType
+}
+
+func (a *testProcessorUpper) SetType() {
+	a.Type = a.GetType()
+}
+
+func (a *testProcessorUpper) Execute(records [][]string) ([][]string, error) {
+	for n, value := range records[a.Row] {
+		records[a.Row][n] = strings.ToUpper(value)
+	}
+	return records, nil
+}
+
+// Processor which does not return its registered type
+type testProcessorUnregistered struct {
+	testProcessorUpper
+}
+
+func (a *testProcessorUnregistered) GetType() ProcessorType {
+	return 0
+}
+
+func TestRegisterProcessor(t *testing.T) {
+	t.Parallel()
+
+	if testProcessorUpperErr != nil {
+		t.Fatalf("error registering processor: %v", testProcessorUpperErr)
+	}
+	if testProcessorUpperType <= ProcessorTypeEnd || testProcessorUpperType.String() != "testUpper" {
+		t.Errorf("unexpected type %d (%s) for registered processor", testProcessorUpperType, testProcessorUpperType)
+	}
+
+	config := Csv{
+		PreProcessor: []Processor{
+			&ProcessorFillRight{Name: "fill", End: Cell{Row: -1, Column: -1}},
+			&testProcessorUpper{Name: "upper", Row: 1},
+		},
+	}
+	configJson, err := json.Marshal(config)
+	if err != nil {
+		t.Fatalf("error marshaling config: %v", err)
+	} else if !strings.Contains(string(configJson), `"Type":"testUpper"`) {
+		t.Errorf("registered processor not marshaled by name: %s", configJson)
+	}
+
+	var configFromJson Csv
+	if err = json.Unmarshal(configJson, &configFromJson); err != nil {
+		t.Fatalf("error unmarshaling config: %v", err)
+	}
+	if !reflect.DeepEqual(config.PreProcessor, configFromJson.PreProcessor) {
+		t.Errorf("processors do not match\nexpected: %v\nreceived: %v", config.PreProcessor, configFromJson.PreProcessor)
+	}
+
+	tests := []struct {
+		name    string
+		factory func() Processor
+	}{
+		{name: "testUpper", factory: func() Processor { return &testProcessorUpper{} }},
+		{name: "mergeRows", factory: func() Processor { return &ProcessorMergeRows{} }},
+		{name: "", factory: func() Processor { return &testProcessorUpper{} }},
+		{name: "testNil", factory: nil},
+	}
+	for n, test := range tests {
+		if _, err := RegisterProcessor(test.name, test.factory); err == nil {
+			t.Errorf("Test %d: expected error registering processor %q", n, test.name)
+		}
+	}
+
+	if err = json.Unmarshal([]byte(`{"PreProcessor": [{"Type": `+strconv.Itoa(int(testProcessorUpperType))+`}]}`), &configFromJson); err == nil {
+		t.Errorf("expected error loading a registered processor by integer")
+	}
+
+	config.PreProcessor = []Processor{&testProcessorUnregistered{testProcessorUpper{Name: "unregistered"}}}
+	if configJson, err = json.Marshal(config); err == nil {
+		t.Errorf("expected error marshaling a processor which is not registered under its type: %s", configJson)
+	}
+}
+
 func TestProcessorMergeColumns(t *testing.T) {
 	input := [][]string{
 		{"r0c0", "r0c1", "r0c2"},
@@ -397,6 +497,405 @@ func TestProcessorMergeRows(t *testing.T) {
 	}
 }
 
+func TestProcessorFillDown(t *testing.T) {
+	input := [][]string{
+		{"Line", "Part", "Count"},
+		{"L1", "A", "10"},
+		{"", "B", "12"},
+		{"", "", ""},
+		{"", "C", "7"},
+		{"L2", "D"},
+		{"", "", "9"},
+		{"", "E", ""},
+	}
+	tests := []testPreProcessor{
+		{
+			input: testCopyInput(input),
+			output: [][]string{
+				{"Line", "Part", "Count"},
+				{"L1", "A", "10"},
+				{"L1", "B", "12"},
+				{"L1", "", ""},
+				{"L1", "C", "7"},
+				{"L2", "D"},
+				{"L2", "", "9"},
+				{"L2", "E", ""},
+			},
+			action: &ProcessorFillDown{
+				Name:  "Test 0",
+				Start: Cell{Row: 1, Column: 0},
+				End:   Cell{Row: -1, Column: 1},
+			},
+		},
+		{
+			input: testCopyInput(input),
+			output: [][]string{
+				{"Line", "Part", "Count"},
+				{"L1", "A", "10"},
+				{"L1", "B", "12"},
+				{"", "", ""},
+				{"", "C", "7"},
+				{"L2", "D"},
+				{"L2", "D", "9"},
+				{"L2", "E", ""},
+			},
+			action: &ProcessorFillDown{
+				Name:           "Test 1",
+				Start:          Cell{Row: 1, Column: 0},
+				End:            Cell{Row: -1, Column: 2},
+				RequireRowData: true,
+			},
+		},
+		{
+			input: testCopyInput(input),
+			output: [][]string{
+				{"Line", "Part", "Count"},
+				{"L1", "A", "10"},
+				{"L1", "B", "12"},
+				{"L1", "B", "12"},
+				{"", "C", "7"},
+				{"L2", "D"},
+				{"L2", "D", "9"},
+				{"L2", "E", "9"},
+			},
+			action: &ProcessorFillDown{
+				Name:        "Test 2",
+				Start:       Cell{Row: 1, Column: 0},
+				End:         Cell{Row: -1, Column: -1},
+				MaxDistance: 2,
+			},
+		},
+		{
+			input: testCopyInput(input),
+			output: [][]string{
+				{"Line", "Part", "Count"},
+				{"L1", "A", "10"},
+				{"", "B", "12"},
+				{"", "", ""},
+				{"", "C", "7"},
+				{"L2", "D"},
+				{"", "", "9"},
+				{"", "E", ""},
+			},
+			action: &ProcessorFillDown{
+				Name:  "Test 3",
+				Start: Cell{Row: 2, Column: 0},
+				End:   Cell{Row: 3, Column: 1},
+			},
+		},
+		{
+			input: [][]string{
+				{"H1", "H2"},
+				{"", "x"},
+				{"", ""},
+				{"v", "y"},
+			},
+			output: [][]string{
+				{"H1", "H2"},
+				{"", "x"},
+				{"", "x"},
+				{"v", "y"},
+			},
+			action: &ProcessorFillDown{
+				Name:  "Test 3a",
+				Start: Cell{Row: 1, Column: 0},
+				End:   Cell{Row: -1, Column: -1},
+			},
+		},
+		{
+			input: testCopyInput(input),
+			action: &ProcessorFillDown{
+				Name:  "Test 4",
+				Start: Cell{Row: -1, Column: 0},
+				End:   Cell{Row: -1, Column: -1},
+			},
+			expectFail: true,
+		},
+		{
+			input: testCopyInput(input),
+			action: &ProcessorFillDown{
+				Name:  "Test 5",
+				Start: Cell{Row: 0, Column: 0},
+				End:   Cell{Row: -1, Column: 3},
+			},
+			expectFail: true,
+		},
+		{
+			input: testCopyInput(input),
+			action: &ProcessorFillDown{
+				Name:  "Test 6",
+				Start: Cell{Row: 0, Column: 0},
+				End:   Cell{Row: 10, Column: -1},
+			},
+			expectFail: true,
+		},
+		{
+			input: testCopyInput(input),
+			action: &ProcessorFillDown{
+				Name:        "Test 7",
+				Start:       Cell{Row: 0, Column: 0},
+				End:         Cell{Row: -1, Column: -1},
+				MaxDistance: -1,
+			},
+			expectFail: true,
+		},
+	}
+
+	for n, test := range tests {
+		output, err := test.action.Execute(test.input)
+		if err != nil {
+			if test.expectFail {
+				continue
+			}
+			t.Errorf("Test %d: error executing: %v", n, err)
+		} else if test.expectFail {
+			t.Errorf("Test %d: expected error", n)
+		} else if !reflect.DeepEqual(test.output, output) {
+			t.Errorf("Test %d: output does not match expectation\nexpected: %v\nreceived: %v", n, test.output, output)
+		}
+	}
+}
+
+func TestProcessorDeleteRows(t *testing.T) {
+	input := [][]string{
+		{"Time", "Temp"},
+		{"s", "°C"},
+		{"0", "21.5"},
+		{"", " "},
+		{"1", "21.7"},
+		{"-----"},
+		{"Total", "43.2"},
+	}
+	tests := []struct {
+		action     *ProcessorDeleteRows
+		output     [][]string
+		removed    int
+		expectFail bool
+	}{
+		{
+			action:  &ProcessorDeleteRows{Name: "Test 0", StartRow: 1, EndRow: 2},
+			output:  [][]string{{"Time", "Temp"}, {"0", "21.5"}, {"", " "}, {"1", "21.7"}, {"-----"}, {"Total", "43.2"}},
+			removed: 1,
+		},
+		{
+			action:  &ProcessorDeleteRows{Name: "Test 1", StartRow: 2, EndRow: -1, Blank: true, Pattern: "^(-+|Total)$"},
+			output:  [][]string{{"Time", "Temp"}, {"s", "°C"}, {"0", "21.5"}, {"1", "21.7"}},
+			removed: 3,
+		},
+		{
+			action:  &ProcessorDeleteRows{Name: "Test 2", StartRow: 0, EndRow: -1, Pattern: "°C", Column: -1},
+			output:  [][]string{{"Time", "Temp"}, {"0", "21.5"}, {"", " "}, {"1", "21.7"}, {"-----"}, {"Total", "43.2"}},
+			removed: 1,
+		},
+		{
+			action:  &ProcessorDeleteRows{Name: "Test 3", StartRow: 0, EndRow: -1, Pattern: "^43", Column: 1},
+			output:  [][]string{{"Time", "Temp"}, {"s", "°C"}, {"0", "21.5"}, {"", " "}, {"1", "21.7"}, {"-----"}},
+			removed: 1,
+		},
+		{
+			action: &ProcessorDeleteRows{Name: "Test 4", StartRow: 0, EndRow: 3, Blank: true},
+			output: testCopyInput(input),
+		},
+		{action: &ProcessorDeleteRows{Name: "Test 5", StartRow: -1, EndRow: -1}, expectFail: true},
+		{action: &ProcessorDeleteRows{Name: "Test 6", StartRow: 3, EndRow: 2}, expectFail: true},
+		{action: &ProcessorDeleteRows{Name: "Test 7", StartRow: 0, EndRow: 10}, expectFail: true},
+		{action: &ProcessorDeleteRows{Name: "Test 8", StartRow: 0, EndRow: -1, Pattern: "("}, expectFail: true},
+	}
+
+	for n, test := range tests {
+		output, removed, err := test.action.ExecuteWithCount(testCopyInput(input))
+		if err != nil {
+			if !test.expectFail {
+				t.Errorf("Test %d: error executing: %v", n, err)
+			}
+			continue
+		} else if test.expectFail {
+			t.Errorf("Test %d: expected error", n)
+			continue
+		}
+		if !reflect.DeepEqual(test.output, output) {
+			t.Errorf("Test %d: output does not match expectation\nexpected: %v\nreceived: %v", n, test.output, output)
+		}
+		if removed != test.removed {
+			t.Errorf("Test %d: expected %d rows removed but received %d", n, test.removed, removed)
+		}
+	}
+}
+
+func TestProcessorDeleteColumns(t *testing.T) {
+	input := [][]string{
+		{"Time", "", "Temp [Up]", "Note", ""},
+		{"0", "", "21.5", "ok"},
+		{"1", " ", "21.7", "", ""},
+	}
+	tests := []struct {
+		action     *ProcessorDeleteColumns
+		output     [][]string
+		removed    int
+		expectFail bool
+	}{
+		{
+			action:  &ProcessorDeleteColumns{Name: "Test 0", StartColumn: 0, EndColumn: -1, Blank: true},
+			output:  [][]string{{"Time", "Temp [Up]", "Note"}, {"0", "21.5", "ok"}, {"1", "21.7", ""}},
+			removed: 2,
+		},
+		{
+			action:  &ProcessorDeleteColumns{Name: "Test 1", StartColumn: 0, EndColumn: -1, Blank: true, Pattern: "^Note$", Row: 0},
+			output:  [][]string{{"Time", "Temp [Up]"}, {"0", "21.5"}, {"1", "21.7"}},
+			removed: 3,
+		},
+		{
+			action:  &ProcessorDeleteColumns{Name: "Test 2", StartColumn: 1, EndColumn: 3},
+			output:  [][]string{{"Time", "Note", ""}, {"0", "ok"}, {"1", "", ""}},
+			removed: 2,
+		},
+		{
+			action:  &ProcessorDeleteColumns{Name: "Test 3", StartColumn: 0, EndColumn: -1, Pattern: "^21\\.7$", Row: -1},
+			output:  [][]string{{"Time", "", "Note", ""}, {"0", "", "ok"}, {"1", " ", "", ""}},
+			removed: 1,
+		},
+		{
+			action: &ProcessorDeleteColumns{Name: "Test 4", StartColumn: 2, EndColumn: 4, Blank: true},
+			output: testCopyInput(input),
+		},
+		{action: &ProcessorDeleteColumns{Name: "Test 5", StartColumn: -1, EndColumn: -1}, expectFail: true},
+		{action: &ProcessorDeleteColumns{Name: "Test 6", StartColumn: 3, EndColumn: 1}, expectFail: true},
+		{action: &ProcessorDeleteColumns{Name: "Test 7", StartColumn: 0, EndColumn: -1, Pattern: "x", Row: 3}, expectFail: true},
+	}
+
+	for n, test := range tests {
+		output, removed, err := test.action.ExecuteWithCount(testCopyInput(input))
+		if err != nil {
+			if !test.expectFail {
+				t.Errorf("Test %d: error executing: %v", n, err)
+			}
+			continue
+		} else if test.expectFail {
+			t.Errorf("Test %d: expected error", n)
+			continue
+		}
+		if !reflect.DeepEqual(test.output, output) {
+			t.Errorf("Test %d: output does not match expectation\nexpected: %v\nreceived: %v", n, test.output, output)
+		}
+		if removed != test.removed {
+			t.Errorf("Test %d: expected %d columns removed but received %d", n, test.removed, removed)
+		}
+	}
+}
+
+func TestProcessorRegexReplace(t *testing.T) {
+	input := [][]string{
+		{"Cycle Time          ", "Speed [Up]", "Speed [Down]"},
+		{"sec", "m/s", "m/s"},
+		{"55.3", "0.148 [Up]", "0.150"},
+		{"56.2", "0.149"},
+	}
+	tests := []testPreProcessor{
+		{
+			input: testCopyInput(input),
+			output: [][]string{
+				{"Cycle Time", "Speed", "Speed [Down]"},
+				{"sec", "m/s", "m/s"},
+				{"55.3", "0.148 [Up]", "0.150"},
+				{"56.2", "0.149"},
+			},
+			action: &ProcessorRegexReplace{
+				Name:        "Test 0",
+				Start:       Cell{Row: 0, Column: 0},
+				End:         Cell{Row: -1, Column: -1},
+				Pattern:     `^\s*(.*?)\s*(\[Up\])?$`,
+				Replacement: "$1",
+				HeaderRows:  []int{0},
+			},
+		},
+		{
+			input: testCopyInput(input),
+			output: [][]string{
+				{"Cycle Time          ", "Speed [Up]", "Speed [Down]"},
+				{"sec", "m/s", "m/s"},
+				{"55.3", "0.148 (Up)", "0.150"},
+				{"56.2", "0.149"},
+			},
+			action: &ProcessorRegexReplace{
+				Name:        "Test 1",
+				Start:       Cell{Row: 2, Column: 1},
+				End:         Cell{Row: -1, Column: 2},
+				Pattern:     `\[(?P<direction>\w+)\]`,
+				Replacement: "(${direction})",
+			},
+		},
+		{
+			input: testCopyInput(input),
+			output: [][]string{
+				{"Cycle Time          ", "Speed [Up]", "Speed [Down]"},
+				{"s", "m/s", "m/s"},
+				{"55.3", "0.148 [Up]", "0.150"},
+				{"56.2", "0.149"},
+			},
+			action: &ProcessorRegexReplace{
+				Name:        "Test 2",
+				Start:       Cell{Row: 0, Column: 0},
+				End:         Cell{Row: 3, Column: 1},
+				Pattern:     `^sec$`,
+				Replacement: "s",
+			},
+		},
+		{
+			input: testCopyInput(input),
+			action: &ProcessorRegexReplace{
+				Name:    "Test 3",
+				Start:   Cell{Row: 0, Column: 0},
+				End:     Cell{Row: -1, Column: 3},
+				Pattern: `x`,
+			},
+			expectFail: true,
+		},
+		{
+			input: testCopyInput(input),
+			action: &ProcessorRegexReplace{
+				Name:  "Test 4",
+				Start: Cell{Row: 0, Column: 0},
+				End:   Cell{Row: -1, Column: -1},
+			},
+			expectFail: true,
+		},
+		{
+			input: testCopyInput(input),
+			action: &ProcessorRegexReplace{
+				Name:    "Test 5",
+				Start:   Cell{Row: 0, Column: 0},
+				End:     Cell{Row: -1, Column: -1},
+				Pattern: `(`,
+			},
+			expectFail: true,
+		},
+		{
+			input: testCopyInput(input),
+			action: &ProcessorRegexReplace{
+				Name:    "Test 6",
+				Start:   Cell{Row: -1, Column: 0},
+				End:     Cell{Row: -1, Column: -1},
+				Pattern: `x`,
+			},
+			expectFail: true,
+		},
+	}
+
+	for n, test := range tests {
+		output, err := test.action.Execute(test.input)
+		if err != nil {
+			if test.expectFail {
+				continue
+			}
+			t.Errorf("Test %d: error executing: %v", n, err)
+		} else if test.expectFail {
+			t.Errorf("Test %d: expected error", n)
+		} else if !reflect.DeepEqual(test.output, output) {
+			t.Errorf("Test %d: output does not match expectation\nexpected: %v\nreceived: %v", n, test.output, output)
+		}
+	}
+}
+
 func TestProcessorFillRight(t *testing.T) {
 	input := [][]string{
 		{"r0c0", "r0c1", "r0c2"},
@@ -0,0 +1,48 @@
+package csvParse
+
+// Information gathered while parsing that did not stop the parse
+type Report struct {
+	Processors []ProcessorReport
+	Tables     []TableReport
+	Warnings   []Warning
+}
+
+// Rows or columns removed by a CountingProcessor
+type ProcessorReport struct {
+	Name    string
+	Type    ProcessorType
+	Removed int
+}
+
+// Rows of a table which did not span the full width of the table or were dropped
+type TableReport struct {
+	Name          string
+	PaddedRows    int // rows where missing cells were set to null
+	SkippedRows   int // rows left out of the table
+	TruncatedRows int // rows where missing cells were left out
+	DroppedRows   int // rows left out by ErrorPolicyDropRow
+}
+
+// A value which was not converted as configured
+type Warning struct {
+	Table  string // blank for cells and concat cells
+	Row    int    // -1 if the warning applies to the whole column
+	Column int
+	Header string // name of the cell or concat cell outside of tables
+	Raw    string // value found in the csv
+	Reason string
+}
+
+func (r *Report) addTable(tableReport *TableReport) {
+	if r == nil {
+		return
+	}
+	r.Tables = append(r.Tables, *tableReport)
+}
+
+func (r *Report) addWarning(warning Warning) {
+	if r == nil {
+		return
+	}
+	r.Warnings = append(r.Warnings, warning)
+}
@@ -2,6 +2,8 @@ package csvParse
 
 import (
 	"fmt"
+	"path"
+	"slices"
 	"strings"
 )
 
@@ -12,7 +14,7 @@ type TableLocation struct {
 	StartCell           Cell
 	EndCell             Cell       // if 0, 0 or equal to start cell it will not execute. Negative values will be treated as the end of the row or column
 	HeaderNames         []string   // Ignored if TableHasHeader is true
-	ColumnDataTypes     []DataType // Ignored if AutoColumnDataTypes is true.
+	ColumnDataTypes     []DataType // Ignored if AutoColumnDataTypes is true or ColumnTypesByHeader is provided.
 	TableHasHeader      bool       // Whether the first row is the header row or not
 	AutoColumnDataTypes bool       // if true will automatically infer column data types from the data
 	SkipBlankData       bool       // Skips returning data for a cell if the cell is blank
@@ -20,6 +22,71 @@ type TableLocation struct {
 	ParseSingleRow      bool       // If true will only take the first row (or row beneath header) regardless of number of rows
 	ParseSeparated      bool       // If true will segment table into multiple maps
 	IgnoreNesting       bool       // If true will not nest the fields under the table name
+	UnitRow             bool       // If true the row beneath the header holds the unit of each column
+	Unpivot             *Unpivot   // If set each value column of a row is returned as its own record
+	Orientation         TableOrientation
+	RaggedRows          RaggedRowPolicy // How rows shorter than the table are handled. Defaults to RaggedRowsError. Vertical tables apply it to the records (columns) missing a cell
+
+	// Data types keyed by header. Keys are matched against the headers as they
+	// appear in the output and can be an exact name, a glob (Alarm*) or a regex
+	// wrapped in slashes (/^Alarm\d+$/). Exact names are checked first followed by
+	// patterns in sorted order. Ignored if AutoColumnDataTypes is true.
+	ColumnTypesByHeader   map[string]DataType
+	DefaultColumnDataType DataType // Used for headers not matched in ColumnTypesByHeader
+
+	// If true a single data type is inferred for each column from its values.
+	// Values which do not match the inferred type are set to null and reported
+	// as warnings. Columns where no type reaches the threshold are strings.
+	InferColumnDataTypes bool
+	InferenceSampleSize  int     // Number of rows used to infer the data types. All rows are used if 0
+	InferenceThreshold   float64 // Fraction of non blank values that must match a data type. Defaults to 1
+
+	Options       *DataTypeOptions            // Options for all columns of the table
+	ColumnOptions map[string]*DataTypeOptions // Options keyed by header. Matched the same as ColumnTypesByHeader
+}
+
+// Handling of rows which have fewer columns than the table
+type RaggedRowPolicy string
+
+const (
+	RaggedRowsError    RaggedRowPolicy = "error"    // Fails the parse. Used if blank
+	RaggedRowsPad      RaggedRowPolicy = "pad"      // Missing cells are set to null
+	RaggedRowsSkip     RaggedRowPolicy = "skip"     // The row is left out of the table
+	RaggedRowsTruncate RaggedRowPolicy = "truncate" // Headers beyond the end of the row are left out of the row. Treated as pad when parsing as an array
+)
+
+// Layout of the headers and records of a table
+type TableOrientation string
+
+const (
+	TableOrientationHorizontal TableOrientation = "horizontal" // Headers are in a row with records in the rows beneath. Used if blank
+	TableOrientationVertical   TableOrientation = "vertical"   // Headers are in a column with records in the columns to the right
+)
+
+// Melts the columns of a table into long format records
+//
+// Example:
+//
+//	table:
+//		Time,Speed,Pressure
+//		ms,m/s,MPa
+//		0,0.1,0.3
+//
+//	action := &Unpivot{
+//		KeyColumns: []string{"Time"},
+//	}
+//
+//	output := []map[string]any{
+//		{"Time": 0, "metric": "Speed", "value": 0.1, "unit": "m/s"},
+//		{"Time": 0, "metric": "Pressure", "value": 0.3, "unit": "MPa"},
+//	}
+type Unpivot struct {
+	KeyColumns   []string          // Headers copied to every record
+	ValueColumns []string          // Headers melted into records. All non key columns are used if empty
+	MetricName   string            // Name of the field holding the header. Defaults to "metric"
+	ValueName    string            // Name of the field holding the value. Defaults to "value"
+	UnitName     string            // Name of the field holding the unit. Defaults to "unit"
+	Units        map[string]string // Unit for a header. Overrides the unit found in the unit row
 }
 
 func NewTableLocation(
@@ -76,25 +143,159 @@ func NewTableLocation(
 
 // used to parse a given table based on a table location from csv records
 func (t *TableLocation) Parse(records [][]string, keepSpaces bool) (string, any, error) {
-	tableName, headers, tableDims, err := t.parseTableHeader(records, keepSpaces)
+	return t.parse(records, keepSpaces, nil, nil)
+}
+
+// parses the table with its options merged over the parent options and records
+// rows that did not match the table width in the report
+func (t *TableLocation) parse(records [][]string, keepSpaces bool, options *DataTypeOptions, report *Report) (string, any, error) {
+	switch t.Orientation {
+	case "", TableOrientationHorizontal:
+		return t.parseHorizontal(records, keepSpaces, options, report, nil)
+	case TableOrientationVertical:
+		return t.parseVertical(records, keepSpaces, options, report)
+	default:
+		return "", nil, fmt.Errorf("invalid orientation: %s", t.Orientation)
+	}
+}
+
+// helper function which parses a table with headers in a row. missing holds the cells
+// of each row which are not in the records and is only set for transposed tables
+func (t *TableLocation) parseHorizontal(records [][]string, keepSpaces bool, options *DataTypeOptions, report *Report, missing []map[int]bool) (string, any, error) {
+	tableName, headers, units, tableDims, err := t.parseTableHeader(records, keepSpaces)
 	if err != nil {
 		return "", nil, fmt.Errorf("error parsing table header: %w", err)
 	}
 
+	table := &tableState{
+		name:    *tableName,
+		dims:    tableDims,
+		headers: headers,
+		units:   units,
+		report:  report,
+	}
+
+	tableReport := &TableReport{Name: table.name}
+	table.rows, err = t.parseTableRows(tableDims, records, missing, tableReport)
+	if err != nil {
+		return table.name, nil, fmt.Errorf("error parsing rows of table %s: %w", table.name, err)
+	}
+
+	table.options, err = t.columnOptions(options.merge(t.Options), table.headers, table.units)
+	if err != nil {
+		return table.name, nil, fmt.Errorf("error finding options for table %s: %w", table.name, err)
+	}
+
+	table.dataTypes, table.inferred, err = t.columnDataTypes(records, table)
+	if err != nil {
+		return table.name, nil, fmt.Errorf("error finding data types for table %s: %w", table.name, err)
+	}
+	if table.inferred {
+		for _, columnOptions := range table.options {
+			if columnOptions.OnError == "" {
+				columnOptions.OnError = ErrorPolicyNull
+			}
+		}
+	}
+
+	table.rows = t.dropRows(records, table, tableReport)
+	report.addTable(tableReport)
+
 	var tableData any
 
 	switch {
+	case t.Unpivot != nil:
+		if t.ParseAsArray || t.ParseSingleRow {
+			return table.name, nil, fmt.Errorf("invalid options selection. Cannot unpivot table %s if parsing as array or single row", table.name)
+		}
+		if n := slices.Index(table.dataTypes, DataTypeSplit); n != -1 {
+			return table.name, nil, fmt.Errorf("invalid options selection. Cannot unpivot table %s with column %s parsed as %s", table.name, table.headers[n], DataTypeSplit)
+		}
+		tableData, err = t.parseTableUnpivot(records, table)
 	case t.ParseAsArray:
-		tableData, err = t.parseTableDataArray(tableDims, records, headers)
+		tableData, err = t.parseTableDataArray(records, table)
 	case t.ParseSingleRow:
-		tableData, err = t.parseTableSingleRow(tableDims, records, headers)
+		tableData, err = t.parseTableSingleRow(records, table)
 	default:
-		tableData, err = t.parseTableData(tableDims, records, headers)
+		tableData, err = t.parseTableData(records, table)
 	}
 	if err != nil {
-		return *tableName, nil, fmt.Errorf("error parsing table %s: %w", *tableName, err)
+		return table.name, nil, fmt.Errorf("error parsing table %s: %w", table.name, err)
 	}
-	return *tableName, tableData, nil
+	return table.name, tableData, nil
+}
+
+// helper function which transposes a vertical table and parses it as a horizontal table
+func (t *TableLocation) parseVertical(records [][]string, keepSpaces bool, options *DataTypeOptions, report *Report) (string, any, error) {
+	var err error
+	tableName := t.Name
+	if tableName == "" {
+		tableName, err = findValue(t.NameLocation, records)
+		if err != nil {
+			return "", nil, fmt.Errorf("error finding name for table: %w", err)
+		}
+		if !keepSpaces {
+			tableName = strings.ReplaceAll(tableName, " ", "_")
+		}
+	}
+
+	if len(records) <= t.StartCell.Row {
+		return "", nil, fmt.Errorf("csv shorter (%d) than table start (%d)", len(records), t.StartCell.Row)
+	}
+	endRow := len(records) - 1
+	if t.EndCell.Row > 0 {
+		endRow = t.EndCell.Row
+	}
+
+	endColumn := t.EndCell.Column
+	if endColumn <= 0 {
+		endColumn = -1
+		for row := t.StartCell.Row; row <= min(endRow, len(records)-1); row++ {
+			endColumn = max(endColumn, len(records[row])-1)
+		}
+	}
+	if endColumn < t.StartCell.Column {
+		return tableName, nil, fmt.Errorf("table start column (%d) is beyond the last column (%d)", t.StartCell.Column, endColumn)
+	}
+
+	// the header and unit cells of a column are not part of its record
+	headerCells := 0
+	if t.TableHasHeader {
+		headerCells++
+	}
+	if t.UnitRow {
+		headerCells++
+	}
+
+	// Cells beyond the end of a short row are blank in the transposed table and kept
+	// as missing so the RaggedRows policy is applied to the records they belong to
+	transposed := make([][]string, endColumn-t.StartCell.Column+1)
+	missing := make([]map[int]bool, len(transposed))
+	for column := range transposed {
+		transposed[column] = make([]string, endRow-t.StartCell.Row+1)
+		for row := range transposed[column] {
+			sourceRow, sourceColumn := t.StartCell.Row+row, t.StartCell.Column+column
+			if sourceRow < len(records) && sourceColumn < len(records[sourceRow]) {
+				transposed[column][row] = records[sourceRow][sourceColumn]
+				continue
+			}
+			if column >= headerCells && (t.RaggedRows == "" || t.RaggedRows == RaggedRowsError) {
+				return tableName, nil, fmt.Errorf("row %d has no cell in column %d of table %s", sourceRow, sourceColumn, tableName)
+			}
+			if missing[column] == nil {
+				missing[column] = make(map[int]bool)
+			}
+			missing[column][row] = true
+		}
+	}
+
+	horizontal := *t
+	horizontal.Name = tableName
+	horizontal.NameLocation = Cell{}
+	horizontal.StartCell = Cell{}
+	horizontal.EndCell = Cell{Row: -1, Column: -1}
+	horizontal.Orientation = TableOrientationHorizontal
+	return horizontal.parseHorizontal(transposed, keepSpaces, options, report, missing)
 }
 
 type tableDimensions struct {
@@ -104,8 +305,33 @@ type tableDimensions struct {
 	endColumn   int
 }
 
+// values of a table found while parsing it
+type tableState struct {
+	name      string
+	dims      *tableDimensions
+	headers   []string
+	units     []string
+	rows      []tableRow
+	dataTypes []DataType
+	inferred  bool // data types were inferred from the column values
+	options   []*DataTypeOptions
+	report    *Report
+}
+
+type tableRow struct {
+	index     int          // row within the records
+	endColumn int          // last column of the row with data. Cells after it are null
+	missing   map[int]bool // columns before endColumn without a cell. Treated the same as cells after endColumn
+	truncated bool         // if true the cells after endColumn are left out instead of null
+}
+
+// reports whether the row has a cell in the column
+func (r tableRow) hasCell(column int) bool {
+	return column <= r.endColumn && !r.missing[column]
+}
+
 // helper function for parsing header data for tables
-func (t *TableLocation) parseTableHeader(records [][]string, keepSpaces bool) (*string, []string, *tableDimensions, error) {
+func (t *TableLocation) parseTableHeader(records [][]string, keepSpaces bool) (*string, []string, []string, *tableDimensions, error) {
 	var err error
 	var tableDims tableDimensions
 
@@ -114,7 +340,7 @@ func (t *TableLocation) parseTableHeader(records [][]string, keepSpaces bool) (*
 	if tableName == "" {
 		tableName, err = findValue(t.NameLocation, records)
 		if err != nil {
-			return nil, nil, nil, fmt.Errorf("error finding name for table: %w", err)
+			return nil, nil, nil, nil, fmt.Errorf("error finding name for table: %w", err)
 		}
 		if !keepSpaces {
 			tableName = strings.ReplaceAll(tableName, " ", "_")
@@ -135,7 +361,7 @@ func (t *TableLocation) parseTableHeader(records [][]string, keepSpaces bool) (*
 		tableDims.endColumn = t.EndCell.Column
 	} else {
 		if len(records) <= t.StartCell.Row {
-			return nil, nil, nil, fmt.Errorf("csv shorter (%d) than table start (%d)", len(records), t.StartCell.Row)
+			return nil, nil, nil, nil, fmt.Errorf("csv shorter (%d) than table start (%d)", len(records), t.StartCell.Row)
 		}
 		tableDims.endColumn = len(records[t.StartCell.Row]) - 1
 	}
@@ -155,94 +381,453 @@ func (t *TableLocation) parseTableHeader(records [][]string, keepSpaces bool) (*
 		}
 	}
 
+	// Parse Units
+	var units []string
+	if t.UnitRow {
+		if tableDims.startRow >= len(records) {
+			return nil, nil, nil, nil, fmt.Errorf("csv shorter (%d) than unit row (%d)", len(records), tableDims.startRow)
+		}
+		units = make([]string, len(headers))
+		for n := range units {
+			column := tableDims.startColumn + n
+			if column < len(records[tableDims.startRow]) {
+				units[n] = strings.TrimSpace(records[tableDims.startRow][column])
+			}
+		}
+		tableDims.startRow += 1
+	}
+
 	errorOnDuplicateName := false
 	if errorOnDuplicateName {
 		for _, header := range headers {
 			if header == "title" {
-				return &tableName, nil, nil, fmt.Errorf("")
+				return &tableName, nil, nil, nil, fmt.Errorf("")
 			}
 		}
 	}
-	return &tableName, headers, &tableDims, nil
+	return &tableName, headers, units, &tableDims, nil
 }
 
-// helper function which parses json style table
-func (t *TableLocation) parseTableData(tableDims *tableDimensions, records [][]string, headers []string) ([]map[string]any, error) {
-	tableData := make([]map[string]any, tableDims.endRow-tableDims.startRow+1)
-	for row := tableDims.startRow; row <= tableDims.endRow; row++ {
-		rowData := make(map[string]any)
-		n := -1
-		for column := tableDims.startColumn; column <= tableDims.endColumn; column++ {
-			n++
-			header := headers[n]
-			rawData, err := findValue(Cell{Row: row, Column: column}, records)
+// helper function which resolves the data type of each header
+func (t *TableLocation) columnDataTypes(records [][]string, table *tableState) (dataTypes []DataType, inferred bool, err error) {
+	dataTypes = make([]DataType, len(table.headers))
+	switch {
+	case t.AutoColumnDataTypes:
+		// DataTypeAuto is the zero value
+	case t.InferColumnDataTypes:
+		threshold := t.InferenceThreshold
+		if threshold <= 0 {
+			threshold = 1
+		}
+		sampleRows := table.rows
+		if t.InferenceSampleSize > 0 && t.InferenceSampleSize < len(sampleRows) {
+			sampleRows = sampleRows[:t.InferenceSampleSize]
+		}
+
+		for n, header := range table.headers {
+			column := table.dims.startColumn + n
+			values := make([]string, 0, len(sampleRows))
+			for _, row := range sampleRows {
+				if row.hasCell(column) && row.index < len(records) && column < len(records[row.index]) &&
+					!table.options[n].isNull(records[row.index][column]) {
+					values = append(values, records[row.index][column])
+				}
+			}
+
+			dataType, confidence := inferDataType(values, threshold, table.options[n])
+			if dataType == DataTypeString && confidence > 0 {
+				table.report.addWarning(Warning{
+					Table:  table.name,
+					Row:    -1,
+					Column: column,
+					Header: header,
+					Reason: fmt.Sprintf("no data type matched at least %g of the values. Best match was %g. Using string", threshold, confidence),
+				})
+			}
+			dataTypes[n] = dataType
+		}
+		return dataTypes, true, nil
+	case len(t.ColumnTypesByHeader) > 0:
+		for n, header := range table.headers {
+			dataType, exists, err := lookupHeader(t.ColumnTypesByHeader, header)
 			if err != nil {
-				return nil, fmt.Errorf("error finding value for cell (%d, %d) with header (%v): %w", row, column, header, err)
+				return nil, false, err
+			} else if !exists {
+				dataType = t.DefaultColumnDataType
 			}
-			dataType := DataTypeAuto
-			if !t.AutoColumnDataTypes {
-				dataType = t.ColumnDataTypes[n]
+			dataTypes[n] = dataType
+		}
+	default:
+		if len(t.ColumnDataTypes) < len(table.headers) {
+			return nil, false, fmt.Errorf("found %d column data types for %d headers", len(t.ColumnDataTypes), len(table.headers))
+		}
+		copy(dataTypes, t.ColumnDataTypes)
+	}
+	return dataTypes, false, nil
+}
+
+// helper function which resolves the options of each header
+//
+// Columns with a unit in the unit row use it as the FromUnit unless one is set
+func (t *TableLocation) columnOptions(tableOptions *DataTypeOptions, headers []string, units []string) ([]*DataTypeOptions, error) {
+	options := make([]*DataTypeOptions, len(headers))
+	for n, header := range headers {
+		override, _, err := lookupHeader(t.ColumnOptions, header)
+		if err != nil {
+			return nil, err
+		}
+		options[n] = tableOptions.merge(override)
+		if options[n].FromUnit == "" && n < len(units) {
+			options[n].FromUnit = units[n]
+		}
+	}
+	return options, nil
+}
+
+// finds the value for a header in a map keyed by header. Exact names are checked
+// first followed by the patterns in sorted order
+func lookupHeader[V any](values map[string]V, header string) (value V, exists bool, err error) {
+	if value, exists = values[header]; exists {
+		return value, true, nil
+	}
+
+	patterns := make([]string, 0, len(values))
+	for pattern := range values {
+		patterns = append(patterns, pattern)
+	}
+	slices.Sort(patterns)
+
+	for _, pattern := range patterns {
+		matched, err := matchHeader(pattern, header)
+		if err != nil {
+			return value, false, err
+		} else if matched {
+			return values[pattern], true, nil
+		}
+	}
+	return value, false, nil
+}
+
+// checks if a header matches a glob or a regex wrapped in slashes
+func matchHeader(pattern string, header string) (bool, error) {
+	if len(pattern) > 1 && strings.HasPrefix(pattern, "/") && strings.HasSuffix(pattern, "/") {
+		regex, err := compileRegexp(pattern[1 : len(pattern)-1])
+		if err != nil {
+			return false, fmt.Errorf("error compiling header pattern: %w", err)
+		}
+		return regex.MatchString(header), nil
+	}
+
+	matched, err := path.Match(pattern, header)
+	if err != nil {
+		return false, fmt.Errorf("error matching header glob %s: %w", pattern, err)
+	}
+	return matched, nil
+}
+
+// helper function which finds the rows of a table and applies the RaggedRows policy to them
+func (t *TableLocation) parseTableRows(tableDims *tableDimensions, records [][]string, missing []map[int]bool, tableReport *TableReport) ([]tableRow, error) {
+	rows := make([]tableRow, 0, tableDims.endRow-tableDims.startRow+1)
+	for row := tableDims.startRow; row <= tableDims.endRow; row++ {
+		length := 0
+		if row < len(records) {
+			length = len(records[row])
+		}
+		var missingCells map[int]bool
+		if row < len(missing) {
+			missingCells = missing[row]
+		}
+		if length > tableDims.endColumn && len(missingCells) == 0 {
+			rows = append(rows, tableRow{index: row, endColumn: tableDims.endColumn})
+			continue
+		}
+		endColumn := min(length-1, tableDims.endColumn)
+
+		switch t.RaggedRows {
+		case "", RaggedRowsError:
+			// out of bound cells are reported when they are read
+			rows = append(rows, tableRow{index: row, endColumn: tableDims.endColumn})
+		case RaggedRowsPad:
+			rows = append(rows, tableRow{index: row, endColumn: endColumn, missing: missingCells})
+			tableReport.PaddedRows++
+		case RaggedRowsSkip:
+			tableReport.SkippedRows++
+		case RaggedRowsTruncate:
+			rows = append(rows, tableRow{index: row, endColumn: endColumn, missing: missingCells, truncated: true})
+			tableReport.TruncatedRows++
+		default:
+			return nil, fmt.Errorf("invalid ragged rows policy: %s", t.RaggedRows)
+		}
+	}
+	return rows, nil
+}
+
+// helper function which reads a cell of the table and converts it to the column data type.
+// The source text of the cell is returned as raw
+//
+// Values which fail to convert are handled by the OnError policy of the column
+func (t *TableLocation) readCell(records [][]string, table *tableState, row int, column int) (data any, raw string, err error) {
+	header := table.headers[column-table.dims.startColumn]
+	dataType := table.dataTypes[column-table.dims.startColumn]
+
+	rawData, err := findValue(Cell{Row: row, Column: column}, records)
+	if err != nil {
+		return nil, "", fmt.Errorf("error finding value for cell (%d, %d) with header (%v): %w", row, column, header, err)
+	}
+	if table.inferred && strings.TrimSpace(rawData) == "" {
+		return nil, rawData, nil
+	}
+	data, err = dataType.ReadWithOptions(rawData, table.options[column-table.dims.startColumn])
+	if err != nil {
+		if table.inferred {
+			err = fmt.Errorf("value does not match inferred data type %s: %w", dataType, err)
+		}
+		warning := Warning{Table: table.name, Row: row, Column: column, Header: header}
+		data, err = table.options[column-table.dims.startColumn].recoverError(err, rawData, warning, table.report)
+		if err != nil {
+			return nil, rawData, fmt.Errorf("error parsing data for cell (%d, %d) with header (%v): %w", row, column, header, err)
+		}
+	}
+	return data, rawData, nil
+}
+
+// helper function which leaves out the rows with a value that fails to convert in a
+// column with ErrorPolicyDropRow
+func (t *TableLocation) dropRows(records [][]string, table *tableState, tableReport *TableReport) []tableRow {
+	var columns []int
+	for n, options := range table.options {
+		if options.OnError == ErrorPolicyDropRow {
+			columns = append(columns, n)
+		}
+	}
+	if len(columns) == 0 {
+		return table.rows
+	}
+
+	rows := make([]tableRow, 0, len(table.rows))
+	for _, row := range table.rows {
+		dropped := false
+		for _, n := range columns {
+			column := table.dims.startColumn + n
+			if row.index >= len(records) || !row.hasCell(column) || column >= len(records[row.index]) {
+				continue
+			}
+			rawData := records[row.index][column]
+			if table.inferred && strings.TrimSpace(rawData) == "" {
+				continue
+			}
+			if _, err := table.dataTypes[n].ReadWithOptions(rawData, table.options[n]); err != nil {
+				table.report.addWarning(Warning{
+					Table:  table.name,
+					Row:    row.index,
+					Column: column,
+					Header: table.headers[n],
+					Raw:    rawData,
+					Reason: fmt.Sprintf("row dropped: %v", err),
+				})
+				dropped = true
+				break
+			}
+		}
+		if dropped {
+			tableReport.DroppedRows++
+			continue
+		}
+		rows = append(rows, row)
+	}
+	return rows
+}
+
+// helper function which parses json style table
+func (t *TableLocation) parseTableData(records [][]string, table *tableState) ([]map[string]any, error) {
+	tableData := make([]map[string]any, 0, len(table.rows))
+	for _, row := range table.rows {
+		rowData := make(map[string]any)
+		for column := table.dims.startColumn; column <= table.dims.endColumn; column++ {
+			if !row.hasCell(column) && row.truncated {
+				continue
 			}
+			header := table.headers[column-table.dims.startColumn]
+
 			var data any
-			data, err = dataType.Read(rawData)
-			if err != nil {
-				return nil, fmt.Errorf("error parsing data for cell (%d, %d) with header (%v): %w", row, column, header, err)
+			var raw string
+			if row.hasCell(column) {
+				var err error
+				data, raw, err = t.readCell(records, table, row.index, column)
+				if err != nil {
+					return nil, err
+				}
 			}
 			if data == nil && t.SkipBlankData {
 				continue
 			}
-			if dataType == DataTypeSplit {
+			if table.dataTypes[column-table.dims.startColumn] == DataTypeSplit {
 				header = fmt.Sprintf("%s_%T", header, data)
 			}
-			rowData[header] = data
+			if err := table.options[column-table.dims.startColumn].setValue(rowData, header, data, raw); err != nil {
+				return nil, err
+			}
 		}
-		tableData[row-tableDims.startRow] = rowData
+		tableData = append(tableData, rowData)
 	}
 	return tableData, nil
 }
 
 // helper function which parses array style table
-func (t *TableLocation) parseTableDataArray(tableDims *tableDimensions, records [][]string, headers []string) (map[string][]any, error) {
+func (t *TableLocation) parseTableDataArray(records [][]string, table *tableState) (map[string][]any, error) {
 	tableData := make(map[string][]any)
-	for column := tableDims.startColumn; column <= tableDims.endColumn; column++ {
-		columnData := make([]any, tableDims.endRow-tableDims.startRow+1)
-		dataType := DataTypeAuto
-		if !t.AutoColumnDataTypes {
-			dataType = t.ColumnDataTypes[column]
-		}
-		for row := tableDims.startRow; row <= tableDims.endRow; row++ {
-			data, err := dataType.Read(records[row][column])
+	for column := table.dims.startColumn; column <= table.dims.endColumn; column++ {
+		options := table.options[column-table.dims.startColumn]
+		columnData := make([]any, len(table.rows))
+		var rawData []any
+		switch options.RawOutput {
+		case "", RawOutputObject:
+		case RawOutputSuffix:
+			rawData = make([]any, len(table.rows))
+		default:
+			return nil, fmt.Errorf("invalid raw output: %s", options.RawOutput)
+		}
+		for n, row := range table.rows {
+			if !row.hasCell(column) {
+				continue
+			}
+			data, raw, err := t.readCell(records, table, row.index, column)
 			if err != nil {
-				return nil, fmt.Errorf("error parsing data for cell (%d, %d) with header (%s): %w", row, column, headers[column], err)
+				return nil, err
 			}
 			if data == nil && t.SkipBlankData {
 				continue
 			}
-			columnData[row-tableDims.startRow] = data
+			columnData[n] = data
+			if options.RawOutput == RawOutputObject {
+				columnData[n] = rawObject(data, raw)
+			} else if rawData != nil {
+				rawData[n] = raw
+			}
+		}
+		header := table.headers[column-table.dims.startColumn]
+		tableData[header] = columnData
+		if rawData != nil {
+			tableData[options.rawName(header)] = rawData
 		}
-		tableData[headers[column]] = columnData
 	}
 	return tableData, nil
 }
 
 // helper function which returns only the first row of a table
-func (t *TableLocation) parseTableSingleRow(tableDims *tableDimensions, records [][]string, headers []string) (map[string]any, error) {
-	tableData := make(map[string]any, len(headers))
-	for column := tableDims.startColumn; column <= tableDims.endColumn; column++ {
-		dataType := DataTypeAuto
-		if !t.AutoColumnDataTypes {
-			dataType = t.ColumnDataTypes[column]
+func (t *TableLocation) parseTableSingleRow(records [][]string, table *tableState) (map[string]any, error) {
+	if len(table.rows) == 0 {
+		return nil, fmt.Errorf("no rows found in table")
+	}
+	row := table.rows[0]
+
+	tableData := make(map[string]any, len(table.headers))
+	for column := table.dims.startColumn; column <= table.dims.endColumn; column++ {
+		if !row.hasCell(column) && row.truncated {
+			continue
 		}
 
-		data, err := dataType.Read(records[tableDims.startRow][column])
-		if err != nil {
-			return nil, fmt.Errorf("error parsing data for cell (%d, %d) with header (%s): %w", tableDims.startRow, column, headers[column], err)
+		var data any
+		var raw string
+		if row.hasCell(column) {
+			var err error
+			data, raw, err = t.readCell(records, table, row.index, column)
+			if err != nil {
+				return nil, err
+			}
 		}
 		if data == nil && t.SkipBlankData {
 			continue
 		}
 
-		tableData[headers[column]] = data
+		if err := table.options[column-table.dims.startColumn].setValue(tableData, table.headers[column-table.dims.startColumn], data, raw); err != nil {
+			return nil, err
+		}
+	}
+	return tableData, nil
+}
+
+// helper function which melts a json style table into one record per row and value column
+func (t *TableLocation) parseTableUnpivot(records [][]string, table *tableState) ([]map[string]any, error) {
+	metricName := t.Unpivot.MetricName
+	if metricName == "" {
+		metricName = "metric"
+	}
+	valueName := t.Unpivot.ValueName
+	if valueName == "" {
+		valueName = "value"
+	}
+	unitName := t.Unpivot.UnitName
+	if unitName == "" {
+		unitName = "unit"
+	}
+
+	headerUnits := make(map[string]string, len(table.headers))
+	for n, header := range table.headers {
+		if n < len(table.units) {
+			headerUnits[header] = table.units[n]
+		}
+	}
+	for header, unit := range t.Unpivot.Units {
+		headerUnits[header] = unit
+	}
+	for n, header := range table.headers {
+		if table.options[n].ToUnit != "" {
+			headerUnits[header] = table.options[n].ToUnit
+		}
+	}
+
+	isKey := make(map[string]bool, len(t.Unpivot.KeyColumns))
+	for _, key := range t.Unpivot.KeyColumns {
+		if !slices.Contains(table.headers, key) {
+			return nil, fmt.Errorf("key column (%s) not found in headers", key)
+		}
+		isKey[key] = true
+	}
+
+	valueColumns := t.Unpivot.ValueColumns
+	if len(valueColumns) == 0 {
+		for _, header := range table.headers {
+			if !isKey[header] {
+				valueColumns = append(valueColumns, header)
+			}
+		}
+	}
+	for _, column := range valueColumns {
+		if !slices.Contains(table.headers, column) {
+			return nil, fmt.Errorf("value column (%s) not found in headers", column)
+		}
+	}
+
+	rowData, err := t.parseTableData(records, table)
+	if err != nil {
+		return nil, err
+	}
+
+	tableData := make([]map[string]any, 0, len(rowData)*len(valueColumns))
+	for _, row := range rowData {
+		for _, column := range valueColumns {
+			value, exists := row[column]
+			if !exists && t.SkipBlankData {
+				continue
+			}
+
+			record := make(map[string]any, len(t.Unpivot.KeyColumns)+3)
+			for _, key := range t.Unpivot.KeyColumns {
+				if keyValue, exists := row[key]; exists {
+					record[key] = keyValue
+				}
+			}
+			record[metricName] = column
+			record[valueName] = value
+			if options := table.options[slices.Index(table.headers, column)]; options.RawOutput == RawOutputSuffix {
+				record[options.rawName(valueName)] = row[options.rawName(column)]
+			}
+			if unit := headerUnits[column]; unit != "" {
+				record[unitName] = unit
+			}
+			tableData = append(tableData, record)
+		}
 	}
 	return tableData, nil
 }
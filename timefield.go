@@ -7,9 +7,10 @@ import (
 )
 
 type TimeField struct {
-	Cells  []Cell // List of cells. Can be concatenated if the time field spans multiple areas
-	Layout string
-	Name   string
+	Cells    []Cell // List of cells. Can be concatenated if the time field spans multiple areas
+	Layout   string
+	Name     string
+	Location string // IANA name of the location. Overrides the location of the Csv if not blank
 }
 
 func NewTimeField(cells []Cell, Layout string) *TimeField {
@@ -20,6 +21,11 @@ func NewTimeField(cells []Cell, Layout string) *TimeField {
 }
 
 func (t *TimeField) Parse(records [][]string) (time.Time, error) {
+	return t.parse(records, nil)
+}
+
+// parses the time field using the location and ambiguous time policy of the options
+func (t *TimeField) parse(records [][]string, options *DataTypeOptions) (time.Time, error) {
 	var timeStr strings.Builder
 	for _, cell := range t.Cells {
 		value, err := findValue(cell, records)
@@ -29,7 +35,7 @@ func (t *TimeField) Parse(records [][]string) (time.Time, error) {
 		timeStr.WriteString(value)
 	}
 
-	timestamp, err := time.ParseInLocation(t.Layout, timeStr.String(), time.Local)
+	timestamp, err := parseTime(t.Layout, timeStr.String(), options.merge(&DataTypeOptions{Location: t.Location}))
 	if err != nil {
 		return time.Time{}, fmt.Errorf("error converting value (%s) to the specified layout (%s): %w", timeStr.String(), t.Layout, err)
 	}
@@ -0,0 +1,95 @@
+package csvParse
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Conversion of a unit to the base unit of its dimension: base = value*scale + offset
+type unit struct {
+	dimension string
+	scale     float64
+	offset    float64
+}
+
+// Built in units keyed by symbol. Temperatures use °C, pressures Pa, lengths m,
+// masses kg and times s as the base unit
+var units = map[string]unit{
+	"°C":   {dimension: "temperature", scale: 1},
+	"degC": {dimension: "temperature", scale: 1},
+	"C":    {dimension: "temperature", scale: 1},
+	"°F":   {dimension: "temperature", scale: 5.0 / 9, offset: -32 * 5.0 / 9},
+	"degF": {dimension: "temperature", scale: 5.0 / 9, offset: -32 * 5.0 / 9},
+	"F":    {dimension: "temperature", scale: 5.0 / 9, offset: -32 * 5.0 / 9},
+	"K":    {dimension: "temperature", scale: 1, offset: -273.15},
+	"Pa":   {dimension: "pressure", scale: 1},
+	"hPa":  {dimension: "pressure", scale: 1e2},
+	"kPa":  {dimension: "pressure", scale: 1e3},
+	"MPa":  {dimension: "pressure", scale: 1e6},
+	"mbar": {dimension: "pressure", scale: 1e2},
+	"bar":  {dimension: "pressure", scale: 1e5},
+	"psi":  {dimension: "pressure", scale: 6894.757293168361},
+	"ksi":  {dimension: "pressure", scale: 6894757.293168361},
+	"atm":  {dimension: "pressure", scale: 101325},
+	"mmHg": {dimension: "pressure", scale: 133.322387415},
+	"torr": {dimension: "pressure", scale: 101325.0 / 760},
+	"inHg": {dimension: "pressure", scale: 3386.389},
+	"um":   {dimension: "length", scale: 1e-6},
+	"µm":   {dimension: "length", scale: 1e-6},
+	"mm":   {dimension: "length", scale: 1e-3},
+	"cm":   {dimension: "length", scale: 1e-2},
+	"m":    {dimension: "length", scale: 1},
+	"km":   {dimension: "length", scale: 1e3},
+	"in":   {dimension: "length", scale: 0.0254},
+	"ft":   {dimension: "length", scale: 0.3048},
+	"yd":   {dimension: "length", scale: 0.9144},
+	"mi":   {dimension: "length", scale: 1609.344},
+	"mg":   {dimension: "mass", scale: 1e-6},
+	"g":    {dimension: "mass", scale: 1e-3},
+	"kg":   {dimension: "mass", scale: 1},
+	"t":    {dimension: "mass", scale: 1e3},
+	"oz":   {dimension: "mass", scale: 0.028349523125},
+	"lb":   {dimension: "mass", scale: 0.45359237},
+	"ns":   {dimension: "time", scale: 1e-9},
+	"us":   {dimension: "time", scale: 1e-6},
+	"µs":   {dimension: "time", scale: 1e-6},
+	"ms":   {dimension: "time", scale: 1e-3},
+	"s":    {dimension: "time", scale: 1},
+	"sec":  {dimension: "time", scale: 1},
+	"min":  {dimension: "time", scale: 60},
+	"h":    {dimension: "time", scale: 3600},
+	"hr":   {dimension: "time", scale: 3600},
+	"d":    {dimension: "time", scale: 86400},
+	"day":  {dimension: "time", scale: 86400},
+}
+
+// finds a unit by symbol. Surrounding whitespace, brackets and parentheses are ignored
+// so units captured from a header row such as [°F] or (psi) are found
+func lookupUnit(symbol string) (unit, error) {
+	trimmed := strings.Trim(strings.TrimSpace(symbol), "[]() ")
+	if u, exists := units[trimmed]; exists {
+		return u, nil
+	}
+	return unit{}, fmt.Errorf("unknown unit: %s", symbol)
+}
+
+// converts a value between two units of the same dimension
+func convertUnit(value float64, from string, to string) (float64, error) {
+	if strings.TrimSpace(from) == "" {
+		return 0, fmt.Errorf("no source unit to convert to %s", to)
+	}
+	source, err := lookupUnit(from)
+	if err != nil {
+		return 0, err
+	}
+	target, err := lookupUnit(to)
+	if err != nil {
+		return 0, err
+	}
+	if source.dimension != target.dimension {
+		return 0, fmt.Errorf("cannot convert %s (%s) to %s (%s)", from, source.dimension, to, target.dimension)
+	}
+
+	base := value*source.scale + source.offset
+	return (base - target.offset) / target.scale, nil
+}